@@ -8,9 +8,12 @@ import (
 )
 
 // Run the f function in new go routine, and return chan to get the value returned by f or the panic value if f panic.
+//
+// if spawn trace is enabled (see [errors.SetSpawnTrace]), the caller locations will be attached to the error.
 func Run(f func() error) <-chan error {
 	ch := make(chan error, 1)
-	go func() { ch <- errors.Catch(f) }()
+	spawned := errors.CaptureSpawn()
+	go func() { ch <- spawned(errors.Catch(f)) }()
 	return ch
 }
 
@@ -22,9 +25,10 @@ type Result[R any] struct {
 // Run2 similar with [Run] but also returning other value not just error.
 func Run2[R any](f func() (R, error)) <-chan Result[R] {
 	ch := make(chan Result[R], 1)
+	spawned := errors.CaptureSpawn()
 	go func() {
 		r, err := errors.Catch2(f)
-		ch <- Result[R]{r, err}
+		ch <- Result[R]{r, spawned(err)}
 	}()
 	return ch
 }
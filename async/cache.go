@@ -0,0 +1,199 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.winto.dev/errors"
+)
+
+// CacheOptions control how [Cache] keep the results.
+//
+// the zero value is valid options, see each field for the default.
+type CacheOptions struct {
+	// how long successful result is kept, zero or negative mean forever (until invalidated).
+	TTL time.Duration
+
+	// how long error is kept, zero or negative mean error is not cached.
+	NegativeTTL time.Duration
+
+	// if positive, successful result that is requested within this duration before its expiry
+	// is refreshed in the background, while the old result is still returned.
+	RefreshAhead time.Duration
+}
+
+// Cache is keyed memoizing cache, concurrent callers for the same key share one in-flight computation.
+type Cache[K comparable, V any] struct {
+	opts CacheOptions
+	f    func(ctx context.Context, key K) (V, error)
+
+	mu      sync.Mutex
+	entries map[K]*cacheEntry[V]
+}
+
+type cacheEntry[V any] struct {
+	value    Result[V]
+	hasValue bool
+	expires  time.Time // zero mean never expired
+
+	pending *cacheCall[V] // in-flight computation, nil if none
+}
+
+type cacheCall[V any] struct {
+	wait      func(context.Context) (V, error)
+	abandoned bool // every waiter gave up before the computation finished
+}
+
+func (e *cacheEntry[V]) valid(now time.Time) bool {
+	return e.hasValue && (e.expires.IsZero() || now.Before(e.expires))
+}
+
+// NewCache create new [Cache] that use f to compute the value of a key.
+//
+// f is run in new goroutine with context that is cancelled when every caller waiting for it gave up (see [PromiseCtx]),
+// the result of such cancelled computation is not cached and the next [Cache.Get] start new one. if f panic, the panic value is converted into error like [Run2].
+func NewCache[K comparable, V any](opts CacheOptions, f func(ctx context.Context, key K) (V, error)) *Cache[K, V] {
+	return &Cache[K, V]{
+		opts:    opts,
+		f:       f,
+		entries: make(map[K]*cacheEntry[V]),
+	}
+}
+
+// Get return the cached result of key, or wait the computation of it.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	for {
+		c.mu.Lock()
+		now := time.Now()
+		e := c.entries[key]
+		if e != nil && e.valid(now) {
+			if c.opts.RefreshAhead > 0 && e.value.Error == nil && e.pending == nil &&
+				!e.expires.IsZero() && e.expires.Sub(now) <= c.opts.RefreshAhead {
+				c.startLocked(key, e)
+			}
+			value := e.value
+			c.mu.Unlock()
+			return value.Result, value.Error
+		}
+
+		if e == nil {
+			e = &cacheEntry[V]{}
+			c.entries[key] = e
+		}
+		if e.pending == nil {
+			c.startLocked(key, e)
+		}
+		call := e.pending
+		c.mu.Unlock()
+
+		v, err := call.wait(ctx)
+		if err != nil && ctx.Err() == nil && c.isAbandoned(call) {
+			// joined right before the other waiters gave up, don't return their cancellation
+			continue
+		}
+		return v, err
+	}
+}
+
+// Refresh start computing key in the background if it is not already in-flight,
+// the old result (if any) is still returned by [Cache.Get] until the new one is ready.
+func (c *Cache[K, V]) Refresh(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.entries[key]
+	if e == nil {
+		e = &cacheEntry[V]{}
+		c.entries[key] = e
+	}
+	if e.pending == nil {
+		c.startLocked(key, e)
+	}
+}
+
+// Invalidate remove the result of key, the result of in-flight computation of key (if any) will not be cached.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// DeleteExpired remove every expired result from the cache,
+// expired result is otherwise kept in memory until its key is requested again.
+func (c *Cache[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range c.entries {
+		if e.pending == nil && !e.valid(now) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *Cache[K, V]) startLocked(key K, e *cacheEntry[V]) {
+	call := &cacheCall[V]{}
+	call.wait = PromiseCtx(func(ctx context.Context) (V, error) {
+		stop := context.AfterFunc(ctx, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.abandonLocked(key, e, call)
+		})
+		v, err := errors.Catch2(func() (V, error) { return c.f(ctx, key) })
+		stop()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if ctx.Err() != nil {
+			c.abandonLocked(key, e, call)
+			return v, err
+		}
+		if c.entries[key] != e || e.pending != call {
+			// invalidated while computing
+			return v, err
+		}
+		e.pending = nil
+
+		now := time.Now()
+		if err != nil && (c.opts.NegativeTTL <= 0 || e.valid(now)) {
+			// not cached, but keep the old result if it is still valid
+			if !e.valid(now) {
+				delete(c.entries, key)
+			}
+			return v, err
+		}
+
+		ttl := c.opts.TTL
+		if err != nil {
+			ttl = c.opts.NegativeTTL
+		}
+		e.value, e.hasValue, e.expires = Result[V]{v, err}, true, time.Time{}
+		if ttl > 0 {
+			e.expires = now.Add(ttl)
+		}
+		return v, err
+	})
+	e.pending = call
+}
+
+// abandonLocked is called when the context of the computation is cancelled because every waiter gave up,
+// the next [Cache.Get] start fresh computation instead of waiting the cancelled one.
+func (c *Cache[K, V]) abandonLocked(key K, e *cacheEntry[V], call *cacheCall[V]) {
+	call.abandoned = true
+	if c.entries[key] != e || e.pending != call {
+		return
+	}
+	e.pending = nil
+	if !e.valid(time.Now()) {
+		delete(c.entries, key)
+	}
+}
+
+func (c *Cache[K, V]) isAbandoned(call *cacheCall[V]) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return call.abandoned
+}
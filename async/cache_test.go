@@ -0,0 +1,176 @@
+package async_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+	"go.winto.dev/errors"
+)
+
+func TestCacheShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	c := async.NewCache(async.CacheOptions{}, func(ctx context.Context, key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return "v:" + key, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Go(func() { results[i], _ = c.Get(context.Background(), "a") })
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, r := range results {
+		if r != "v:a" {
+			t.Fatalf("unexpected result: %v", results)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("concurrent callers should share one computation, got %d calls", calls.Load())
+	}
+
+	c.Get(context.Background(), "a")
+	c.Get(context.Background(), "b")
+	if calls.Load() != 2 {
+		t.Fatalf("result should be cached per key, got %d calls", calls.Load())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	var calls atomic.Int32
+	errTest := errors.New("testerr")
+	c := async.NewCache(async.CacheOptions{TTL: 30 * time.Millisecond, NegativeTTL: 30 * time.Millisecond}, func(ctx context.Context, key string) (int, error) {
+		n := int(calls.Add(1))
+		if key == "err" {
+			return 0, errTest
+		}
+		return n, nil
+	})
+	ctx := context.Background()
+
+	if v, _ := c.Get(ctx, "ok"); v != 1 {
+		t.Fatalf("unexpected value %d", v)
+	}
+	if v, _ := c.Get(ctx, "ok"); v != 1 {
+		t.Fatalf("result should be cached within TTL")
+	}
+	if _, err := c.Get(ctx, "err"); err != errTest {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := c.Get(ctx, "err"); err != errTest || calls.Load() != 2 {
+		t.Fatalf("error should be cached within NegativeTTL")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if v, _ := c.Get(ctx, "ok"); v != 3 {
+		t.Fatalf("result should be recomputed after TTL, got %d", v)
+	}
+	c.Get(ctx, "err")
+	if calls.Load() != 4 {
+		t.Fatalf("error should be recomputed after NegativeTTL")
+	}
+
+	calls.Store(0)
+	c = async.NewCache(async.CacheOptions{}, func(ctx context.Context, key string) (int, error) {
+		calls.Add(1)
+		return 0, errTest
+	})
+	c.Get(ctx, "a")
+	c.Get(ctx, "a")
+	if calls.Load() != 2 {
+		t.Fatalf("error should not be cached without NegativeTTL")
+	}
+}
+
+func TestCacheInvalidateInFlight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	c := async.NewCache(async.CacheOptions{}, func(ctx context.Context, key string) (int32, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-release
+		}
+		return n, nil
+	})
+
+	first := make(chan int32)
+	go func() {
+		v, _ := c.Get(context.Background(), "a")
+		first <- v
+	}()
+	eventually(t, "computation should be started", func() bool { return calls.Load() == 1 })
+
+	c.Invalidate("a")
+	if v, _ := c.Get(context.Background(), "a"); v != 2 {
+		t.Fatalf("Get after Invalidate should start new computation, got %d", v)
+	}
+
+	close(release)
+	if v := <-first; v != 1 {
+		t.Fatalf("the waiter of invalidated computation should still get its result, got %d", v)
+	}
+	if v, _ := c.Get(context.Background(), "a"); v != 2 {
+		t.Fatalf("the result of invalidated computation should not be cached, got %d", v)
+	}
+}
+
+func TestCacheRefreshAhead(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{}, 1)
+	c := async.NewCache(async.CacheOptions{TTL: 50 * time.Millisecond, RefreshAhead: 40 * time.Millisecond}, func(ctx context.Context, key string) (int32, error) {
+		n := calls.Add(1)
+		if n > 1 {
+			<-release
+		}
+		return n, nil
+	})
+	ctx := context.Background()
+
+	c.Get(ctx, "a")
+	time.Sleep(20 * time.Millisecond)
+	if v, _ := c.Get(ctx, "a"); v != 1 {
+		t.Fatalf("old result should be returned while refreshing, got %d", v)
+	}
+	eventually(t, "result near expiry should be refreshed in the background", func() bool { return calls.Load() == 2 })
+	if v, _ := c.Get(ctx, "a"); v != 1 || calls.Load() != 2 {
+		t.Fatalf("refresh should not be started twice")
+	}
+
+	release <- struct{}{}
+	eventually(t, "refreshed result should be cached", func() bool {
+		v, _ := c.Get(ctx, "a")
+		return v == 2
+	})
+}
+
+func TestCacheAbandoned(t *testing.T) {
+	var calls atomic.Int32
+	c := async.NewCache(async.CacheOptions{}, func(ctx context.Context, key string) (int32, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return n, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, "a"); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	v, err := c.Get(context.Background(), "a")
+	if err != nil || v != 2 {
+		t.Fatalf("Get after every waiter gave up should start new computation, got %d, %v", v, err)
+	}
+}
@@ -0,0 +1,261 @@
+package async
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.winto.dev/errors"
+)
+
+type lockDebugConfig struct {
+	threshold time.Duration
+	report    func(holder []errors.Location)
+}
+
+var lockDebug atomic.Pointer[lockDebugConfig]
+
+// SetLockDebug enable debug mode for [CtxMutex] and [CtxRWMutex],
+// the stack of the goroutine holding the write lock is recorded (see [CtxMutex.Holder]),
+// and report is called if the lock is held longer than threshold.
+//
+// nil report mean log the holder with [slog.Warn]. zero or negative threshold disable debug mode (the default).
+//
+// the stack is captured regardless of [errors.StackPolicy], and capturing it is not cheap,
+// so this is intended for diagnosing stuck goroutines, not for production.
+func SetLockDebug(threshold time.Duration, report func(holder []errors.Location)) {
+	if threshold <= 0 {
+		lockDebug.Store(nil)
+		return
+	}
+	if report == nil {
+		report = func(holder []errors.Location) {
+			locs := make([]string, len(holder))
+			for i := range holder {
+				locs[i] = holder[i].String()
+			}
+			slog.Warn("async: lock held too long", "threshold", threshold, "holder", locs)
+		}
+	}
+	lockDebug.Store(&lockDebugConfig{threshold, report})
+}
+
+type lockHolder struct {
+	mu     sync.Mutex
+	holder []errors.Location
+	timer  *time.Timer
+}
+
+func (h *lockHolder) acquired() {
+	cfg := lockDebug.Load()
+	if cfg == nil {
+		return
+	}
+
+	locs := errors.Callers(0)
+	for len(locs) > 0 && strings.HasPrefix(locs[0].Func(), "go.winto.dev/async.") {
+		locs = locs[1:]
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.holder = locs
+	h.timer = time.AfterFunc(cfg.threshold, func() { cfg.report(locs) })
+}
+
+func (h *lockHolder) released() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	h.holder, h.timer = nil, nil
+}
+
+func (h *lockHolder) get() []errors.Location {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.holder
+}
+
+// CtxMutex is like [Mutex], but the lock can be cancelled by context.
+//
+// the zero value is unlocked mutex.
+type CtxMutex struct {
+	once   sync.Once
+	sem    Sem
+	holder lockHolder
+}
+
+func (m *CtxMutex) init() Sem {
+	m.once.Do(func() { m.sem = NewSem(1) })
+	return m.sem
+}
+
+func (m *CtxMutex) Lock() {
+	m.init().Acquire(context.Background(), 1)
+	m.holder.acquired()
+}
+
+// LockCtx is like [CtxMutex.Lock], but give up and return ctx.Err() when ctx is done.
+func (m *CtxMutex) LockCtx(ctx context.Context) error {
+	if err := m.init().Acquire(ctx, 1); err != nil {
+		return err
+	}
+	m.holder.acquired()
+	return nil
+}
+
+func (m *CtxMutex) TryLock() bool {
+	if !m.init().TryAcquire(1) {
+		return false
+	}
+	m.holder.acquired()
+	return true
+}
+
+// TryLockTimeout is like [CtxMutex.TryLock], but wait up to timeout for the lock.
+func (m *CtxMutex) TryLockTimeout(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.LockCtx(ctx) == nil
+}
+
+func (m *CtxMutex) Unlock() {
+	m.holder.released()
+	m.init().Release(1)
+}
+
+// Holder return the stack of the goroutine holding the lock, only available in debug mode, see [SetLockDebug].
+func (m *CtxMutex) Holder() []errors.Location { return m.holder.get() }
+
+// Run runs a function with mutex control.
+func (m *CtxMutex) Run(f func()) {
+	m.Lock()
+	defer m.Unlock()
+	f()
+}
+
+// RunCtx runs a function with mutex control, or return ctx.Err() if ctx is done before the lock is acquired.
+func (m *CtxMutex) RunCtx(ctx context.Context, f func(ctx context.Context) error) error {
+	if err := m.LockCtx(ctx); err != nil {
+		return err
+	}
+	defer m.Unlock()
+	return f(ctx)
+}
+
+const ctxRWMutexMaxReaders = 1 << 30
+
+// CtxRWMutex is like [RWMutex], but the lock can be cancelled by context.
+//
+// waiting writer block new readers, so writer is not starved.
+// the zero value is unlocked mutex.
+type CtxRWMutex struct {
+	once   sync.Once
+	sem    Sem
+	holder lockHolder
+}
+
+func (m *CtxRWMutex) init() Sem {
+	m.once.Do(func() { m.sem = NewSem(ctxRWMutexMaxReaders) })
+	return m.sem
+}
+
+func (m *CtxRWMutex) Lock() {
+	m.init().Acquire(context.Background(), ctxRWMutexMaxReaders)
+	m.holder.acquired()
+}
+
+// LockCtx is like [CtxRWMutex.Lock], but give up and return ctx.Err() when ctx is done.
+func (m *CtxRWMutex) LockCtx(ctx context.Context) error {
+	if err := m.init().Acquire(ctx, ctxRWMutexMaxReaders); err != nil {
+		return err
+	}
+	m.holder.acquired()
+	return nil
+}
+
+func (m *CtxRWMutex) TryLock() bool {
+	if !m.init().TryAcquire(ctxRWMutexMaxReaders) {
+		return false
+	}
+	m.holder.acquired()
+	return true
+}
+
+// TryLockTimeout is like [CtxRWMutex.TryLock], but wait up to timeout for the lock.
+func (m *CtxRWMutex) TryLockTimeout(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.LockCtx(ctx) == nil
+}
+
+func (m *CtxRWMutex) Unlock() {
+	m.holder.released()
+	m.init().Release(ctxRWMutexMaxReaders)
+}
+
+func (m *CtxRWMutex) RLock() {
+	m.init().Acquire(context.Background(), 1)
+}
+
+// RLockCtx is like [CtxRWMutex.RLock], but give up and return ctx.Err() when ctx is done.
+func (m *CtxRWMutex) RLockCtx(ctx context.Context) error {
+	return m.init().Acquire(ctx, 1)
+}
+
+func (m *CtxRWMutex) TryRLock() bool {
+	return m.init().TryAcquire(1)
+}
+
+// TryRLockTimeout is like [CtxRWMutex.TryRLock], but wait up to timeout for the lock.
+func (m *CtxRWMutex) TryRLockTimeout(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.RLockCtx(ctx) == nil
+}
+
+func (m *CtxRWMutex) RUnlock() {
+	m.init().Release(1)
+}
+
+// Holder return the stack of the goroutine holding the write lock, only available in debug mode, see [SetLockDebug].
+//
+// read lock holders are not recorded.
+func (m *CtxRWMutex) Holder() []errors.Location { return m.holder.get() }
+
+// Run runs a function with mutex control.
+func (m *CtxRWMutex) Run(f func()) {
+	m.Lock()
+	defer m.Unlock()
+	f()
+}
+
+// RunCtx runs a function with mutex control, or return ctx.Err() if ctx is done before the lock is acquired.
+func (m *CtxRWMutex) RunCtx(ctx context.Context, f func(ctx context.Context) error) error {
+	if err := m.LockCtx(ctx); err != nil {
+		return err
+	}
+	defer m.Unlock()
+	return f(ctx)
+}
+
+// RunRead runs a function with mutex control for read-only data.
+func (m *CtxRWMutex) RunRead(f func()) {
+	m.RLock()
+	defer m.RUnlock()
+	f()
+}
+
+// RunReadCtx is like [CtxRWMutex.RunRead], but return ctx.Err() if ctx is done before the lock is acquired.
+func (m *CtxRWMutex) RunReadCtx(ctx context.Context, f func(ctx context.Context) error) error {
+	if err := m.RLockCtx(ctx); err != nil {
+		return err
+	}
+	defer m.RUnlock()
+	return f(ctx)
+}
@@ -0,0 +1,106 @@
+package async_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+	"go.winto.dev/errors"
+)
+
+func TestCtxMutexLockCtx(t *testing.T) {
+	var m async.CtxMutex
+	m.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.LockCtx(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("CtxMutex.LockCtx should return ctx.Err(), got %v", err)
+	}
+	if m.TryLockTimeout(10 * time.Millisecond) {
+		t.Fatalf("CtxMutex.TryLockTimeout should fail while locked")
+	}
+
+	m.Unlock()
+	if !m.TryLock() {
+		t.Fatalf("cancelled CtxMutex.LockCtx should not hold the lock")
+	}
+	m.Unlock()
+}
+
+func TestCtxRWMutexWriterPreference(t *testing.T) {
+	var m async.CtxRWMutex
+	m.RLock()
+	if !m.TryRLock() {
+		t.Fatalf("multiple readers should hold the lock together")
+	}
+	m.RUnlock()
+
+	locked := make(chan struct{})
+	go func() {
+		m.Lock()
+		close(locked)
+	}()
+	eventually(t, "waiting writer should block new readers", func() bool {
+		if m.TryRLock() {
+			m.RUnlock()
+			return false
+		}
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if m.RLockCtx(ctx) == nil {
+		t.Fatalf("CtxRWMutex.RLockCtx should wait behind the writer")
+	}
+
+	m.RUnlock()
+	<-locked
+	if m.TryRLock() {
+		t.Fatalf("reader should not hold the lock with writer")
+	}
+	m.Unlock()
+	if !m.TryRLock() {
+		t.Fatalf("reader should hold the lock after the writer unlocked")
+	}
+	m.RUnlock()
+}
+
+func lockHolderFunc(m *async.CtxMutex) { m.Lock() }
+
+func TestCtxMutexDebug(t *testing.T) {
+	// the holder must be recorded even when error stack trace is disabled
+	errors.SetStackPolicy(errors.StackPolicy{Disabled: true})
+	defer errors.SetStackPolicy(errors.StackPolicy{})
+
+	reported := make(chan []errors.Location, 1)
+	async.SetLockDebug(10*time.Millisecond, func(holder []errors.Location) { reported <- holder })
+	defer async.SetLockDebug(0, nil)
+
+	var m async.CtxMutex
+	lockHolderFunc(&m)
+
+	haveHolder := func(locs []errors.Location) bool {
+		return len(locs) > 0 && strings.HasSuffix(locs[0].Func(), "lockHolderFunc")
+	}
+	if !haveHolder(m.Holder()) {
+		t.Fatalf("CtxMutex.Holder should start at the caller of Lock, got %v", m.Holder())
+	}
+
+	select {
+	case holder := <-reported:
+		if !haveHolder(holder) {
+			t.Fatalf("invalid reported holder: %v", holder)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lock held longer than threshold should be reported")
+	}
+
+	m.Unlock()
+	if m.Holder() != nil {
+		t.Fatalf("CtxMutex.Holder should be nil after unlocked")
+	}
+}
@@ -0,0 +1,95 @@
+package async
+
+import (
+	"context"
+	"sync"
+
+	"go.winto.dev/errors"
+)
+
+// Group is a collection of tasks running in their own goroutine, see [NewGroup].
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    *Sem
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup create new [Group] and derived context from ctx.
+//
+// the derived context is cancelled when the first task failed or [Group.Wait] returned,
+// whichever come first.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{ctx: ctx, cancel: cancel}, ctx
+}
+
+// NewGroupWithLimit is similar to [NewGroup] but at most limit tasks are running concurrently,
+// zero or negative limit mean unlimited.
+func NewGroupWithLimit(ctx context.Context, limit int) (*Group, context.Context) {
+	g, ctx := NewGroup(ctx)
+	if limit <= 0 {
+		return g, ctx
+	}
+	sem := NewSem(limit)
+	g.sem = &sem
+	return g, ctx
+}
+
+// Go run f in new goroutine with the derived context, if f panic, the panic value is converted into error.
+//
+// if the group have limit, f will wait in the new goroutine until it can run,
+// f will not run at all if the derived context is done while waiting.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	spawned := errors.CaptureSpawn()
+	g.wg.Go(func() {
+		if g.sem != nil {
+			if g.sem.Acquire(g.ctx, 1) != nil {
+				return
+			}
+			defer g.sem.Release(1)
+		}
+
+		err := spawned(errors.Catch(func() error { return f(g.ctx) }))
+		if err == nil {
+			return
+		}
+
+		g.mu.Lock()
+		if len(g.errs) > 0 && errors.Is(err, context.Canceled) {
+			// cancelled by the group because other task failed, that error is already recorded
+			g.mu.Unlock()
+			return
+		}
+		g.errs = append(g.errs, err)
+		g.mu.Unlock()
+		g.cancel(err)
+	})
+}
+
+// Wait for all tasks to finish, and return all errors returned by them joined with [errors.Join],
+// except [context.Canceled] returned after other task failed, since the group cancelled them.
+//
+// if there is only one error, it will be returned as is.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return joinErrs(g.errs)
+}
+
+func joinErrs(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,140 @@
+package async_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+	"go.winto.dev/errors"
+)
+
+// track the maximum number of concurrent calls
+type concurrency struct{ running, max atomic.Int32 }
+
+func (c *concurrency) enter() {
+	n := c.running.Add(1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
+
+func (c *concurrency) exit() { c.running.Add(-1) }
+
+// wait until f return true, or fail the test after a while
+func eventually(t *testing.T, msg string, f func() bool) {
+	t.Helper()
+	for range 500 {
+		if f() {
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	t.Fatal(msg)
+}
+
+func TestGroupFirstErrorCancel(t *testing.T) {
+	errTest := errors.New("testerr")
+	g, ctx := async.NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error { return errTest })
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if err := g.Wait(); !errors.Is(err, errTest) {
+		t.Fatalf("Group.Wait should return the task error, got %v", err)
+	}
+	if !errors.Is(context.Cause(ctx), errTest) {
+		t.Fatalf("the context should be cancelled with the first error as the cause")
+	}
+}
+
+func TestGroupSiblingCancelled(t *testing.T) {
+	errTest := errors.New("testerr")
+	g, _ := async.NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error { return errTest })
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.Trace(ctx.Err())
+	})
+
+	if err := g.Wait(); !errors.Is(err, errTest) || errors.Is(err, context.Canceled) {
+		t.Fatalf("Group.Wait should not return the cancellation caused by the group itself, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ = async.NewGroup(ctx)
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Group.Wait should return the cancellation of the parent context, got %v", err)
+	}
+}
+
+func TestGroupJoinErrors(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	g, _ := async.NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error { return errA })
+	g.Go(func(ctx context.Context) error { return errB })
+	g.Go(func(ctx context.Context) error { return nil })
+
+	err := g.Wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Group.Wait should join every error, got %v", err)
+	}
+
+	g, ctx := async.NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error { return nil })
+	if g.Wait() != nil || ctx.Err() == nil {
+		t.Fatalf("Group.Wait should return nil and cancel the context")
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	g, _ := async.NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error { panic("testpanic") })
+
+	err := g.Wait()
+	if err == nil || !strings.Contains(err.Error(), "testpanic") || errors.StackTrace(err) == nil {
+		t.Fatalf("panic should be converted into traced error, got %v", err)
+	}
+}
+
+func TestGroupLimit(t *testing.T) {
+	for _, limit := range []int{2, 0, -1} {
+		g, _ := async.NewGroupWithLimit(context.Background(), limit)
+		var c concurrency
+		for range 10 {
+			g.Go(func(ctx context.Context) error {
+				c.enter()
+				defer c.exit()
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			})
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- g.Wait() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Group.Wait with limit %d should not hang", limit)
+		}
+
+		if limit > 0 && c.max.Load() > int32(limit) {
+			t.Fatalf("at most %d tasks should run concurrently, got %d", limit, c.max.Load())
+		}
+	}
+}
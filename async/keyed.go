@@ -0,0 +1,222 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// keyedLocks hold per-key lock, the lock is freed when nobody hold or wait for it
+type keyedLocks[K comparable, M any] struct {
+	mu    sync.Mutex
+	locks map[K]*keyedLock[M]
+}
+
+type keyedLock[M any] struct {
+	m    M
+	refs int
+}
+
+func (l *keyedLocks[K, M]) ref(key K) *M {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks == nil {
+		l.locks = make(map[K]*keyedLock[M])
+	}
+	e := l.locks[key]
+	if e == nil {
+		e = &keyedLock[M]{}
+		l.locks[key] = e
+	}
+	e.refs++
+	return &e.m
+}
+
+func (l *keyedLocks[K, M]) get(key K) *M {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.locks[key]
+	if e == nil {
+		panic("async: unlock of unlocked key")
+	}
+	return &e.m
+}
+
+func (l *keyedLocks[K, M]) unref(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.locks[key]
+	e.refs--
+	if e.refs == 0 {
+		delete(l.locks, key)
+	}
+}
+
+func (l *keyedLocks[K, M]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
+
+// KeyedMutex is set of [CtxMutex] keyed by K, the lock of a key is created lazily and freed when idle.
+//
+// the zero value is ready to use.
+type KeyedMutex[K comparable] struct {
+	locks keyedLocks[K, CtxMutex]
+}
+
+func (m *KeyedMutex[K]) Lock(key K) {
+	m.locks.ref(key).Lock()
+}
+
+// LockCtx is like [KeyedMutex.Lock], but give up and return ctx.Err() when ctx is done.
+func (m *KeyedMutex[K]) LockCtx(ctx context.Context, key K) error {
+	if err := m.locks.ref(key).LockCtx(ctx); err != nil {
+		m.locks.unref(key)
+		return err
+	}
+	return nil
+}
+
+func (m *KeyedMutex[K]) TryLock(key K) bool {
+	if !m.locks.ref(key).TryLock() {
+		m.locks.unref(key)
+		return false
+	}
+	return true
+}
+
+func (m *KeyedMutex[K]) Unlock(key K) {
+	m.locks.get(key).Unlock()
+	m.locks.unref(key)
+}
+
+// Len return the number of keys currently locked or waited.
+func (m *KeyedMutex[K]) Len() int { return m.locks.len() }
+
+// Run runs a function with mutex control of key.
+func (m *KeyedMutex[K]) Run(key K, f func()) {
+	m.Lock(key)
+	defer m.Unlock(key)
+	f()
+}
+
+func (m *KeyedMutex[K]) RunE(key K, f func() error) error {
+	m.Lock(key)
+	defer m.Unlock(key)
+	return f()
+}
+
+// RunCtx runs a function with mutex control of key, or return ctx.Err() if ctx is done before the lock is acquired.
+func (m *KeyedMutex[K]) RunCtx(ctx context.Context, key K, f func(ctx context.Context) error) error {
+	if err := m.LockCtx(ctx, key); err != nil {
+		return err
+	}
+	defer m.Unlock(key)
+	return f(ctx)
+}
+
+// KeyedRWMutex is set of [CtxRWMutex] keyed by K, the lock of a key is created lazily and freed when idle.
+//
+// the zero value is ready to use.
+type KeyedRWMutex[K comparable] struct {
+	locks keyedLocks[K, CtxRWMutex]
+}
+
+func (m *KeyedRWMutex[K]) Lock(key K) {
+	m.locks.ref(key).Lock()
+}
+
+// LockCtx is like [KeyedRWMutex.Lock], but give up and return ctx.Err() when ctx is done.
+func (m *KeyedRWMutex[K]) LockCtx(ctx context.Context, key K) error {
+	if err := m.locks.ref(key).LockCtx(ctx); err != nil {
+		m.locks.unref(key)
+		return err
+	}
+	return nil
+}
+
+func (m *KeyedRWMutex[K]) TryLock(key K) bool {
+	if !m.locks.ref(key).TryLock() {
+		m.locks.unref(key)
+		return false
+	}
+	return true
+}
+
+func (m *KeyedRWMutex[K]) Unlock(key K) {
+	m.locks.get(key).Unlock()
+	m.locks.unref(key)
+}
+
+func (m *KeyedRWMutex[K]) RLock(key K) {
+	m.locks.ref(key).RLock()
+}
+
+// RLockCtx is like [KeyedRWMutex.RLock], but give up and return ctx.Err() when ctx is done.
+func (m *KeyedRWMutex[K]) RLockCtx(ctx context.Context, key K) error {
+	if err := m.locks.ref(key).RLockCtx(ctx); err != nil {
+		m.locks.unref(key)
+		return err
+	}
+	return nil
+}
+
+func (m *KeyedRWMutex[K]) TryRLock(key K) bool {
+	if !m.locks.ref(key).TryRLock() {
+		m.locks.unref(key)
+		return false
+	}
+	return true
+}
+
+func (m *KeyedRWMutex[K]) RUnlock(key K) {
+	m.locks.get(key).RUnlock()
+	m.locks.unref(key)
+}
+
+// Len return the number of keys currently locked or waited.
+func (m *KeyedRWMutex[K]) Len() int { return m.locks.len() }
+
+// Run runs a function with mutex control of key.
+func (m *KeyedRWMutex[K]) Run(key K, f func()) {
+	m.Lock(key)
+	defer m.Unlock(key)
+	f()
+}
+
+func (m *KeyedRWMutex[K]) RunE(key K, f func() error) error {
+	m.Lock(key)
+	defer m.Unlock(key)
+	return f()
+}
+
+// RunCtx runs a function with mutex control of key, or return ctx.Err() if ctx is done before the lock is acquired.
+func (m *KeyedRWMutex[K]) RunCtx(ctx context.Context, key K, f func(ctx context.Context) error) error {
+	if err := m.LockCtx(ctx, key); err != nil {
+		return err
+	}
+	defer m.Unlock(key)
+	return f(ctx)
+}
+
+// RunRead runs a function with mutex control of key for read-only data.
+func (m *KeyedRWMutex[K]) RunRead(key K, f func()) {
+	m.RLock(key)
+	defer m.RUnlock(key)
+	f()
+}
+
+func (m *KeyedRWMutex[K]) RunERead(key K, f func() error) error {
+	m.RLock(key)
+	defer m.RUnlock(key)
+	return f()
+}
+
+// RunReadCtx is like [KeyedRWMutex.RunRead], but return ctx.Err() if ctx is done before the lock is acquired.
+func (m *KeyedRWMutex[K]) RunReadCtx(ctx context.Context, key K, f func(ctx context.Context) error) error {
+	if err := m.RLockCtx(ctx, key); err != nil {
+		return err
+	}
+	defer m.RUnlock(key)
+	return f(ctx)
+}
@@ -0,0 +1,99 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+)
+
+func TestKeyedMutexFreed(t *testing.T) {
+	var m async.KeyedMutex[string]
+	m.Lock("a")
+	if m.Len() != 1 {
+		t.Fatalf("locked key should be kept")
+	}
+
+	if m.TryLock("a") || m.Len() != 1 {
+		t.Fatalf("failed KeyedMutex.TryLock should not keep extra reference")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.LockCtx(ctx, "a"); err != context.DeadlineExceeded {
+		t.Fatalf("KeyedMutex.LockCtx should return ctx.Err(), got %v", err)
+	}
+
+	m.Unlock("a")
+	if m.Len() != 0 {
+		t.Fatalf("idle key should be freed, got %d keys", m.Len())
+	}
+
+	m.Run("a", func() {})
+	if m.Len() != 0 {
+		t.Fatalf("idle key should be freed after KeyedMutex.Run")
+	}
+}
+
+func TestKeyedMutexIndependentKeys(t *testing.T) {
+	var m async.KeyedMutex[string]
+	m.Lock("a")
+	defer m.Unlock("a")
+
+	if !m.TryLock("b") {
+		t.Fatalf("different key should not be blocked")
+	}
+	m.Unlock("b")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := m.RunCtx(ctx, "b", func(ctx context.Context) error {
+		if m.Len() != 2 {
+			t.Errorf("every locked key should be kept, got %d keys", m.Len())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyedRWMutexFreed(t *testing.T) {
+	var m async.KeyedRWMutex[int]
+	m.RLock(1)
+	m.RLock(1)
+	if m.TryLock(1) || m.Len() != 1 {
+		t.Fatalf("writer should be blocked by readers, and failed TryLock should not keep extra reference")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.LockCtx(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("KeyedRWMutex.LockCtx should return ctx.Err(), got %v", err)
+	}
+	if !m.TryLock(2) {
+		t.Fatalf("different key should not be blocked")
+	}
+	m.Unlock(2)
+
+	m.RUnlock(1)
+	m.RUnlock(1)
+	if m.Len() != 0 {
+		t.Fatalf("idle key should be freed, got %d keys", m.Len())
+	}
+
+	m.Lock(1)
+	if m.TryRLock(1) || m.Len() != 1 {
+		t.Fatalf("failed KeyedRWMutex.TryRLock should not keep extra reference")
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.RLockCtx(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("KeyedRWMutex.RLockCtx should return ctx.Err(), got %v", err)
+	}
+	m.Unlock(1)
+	if m.Len() != 0 {
+		t.Fatalf("idle key should be freed, got %d keys", m.Len())
+	}
+}
@@ -0,0 +1,160 @@
+package async
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"slices"
+	"sync"
+
+	"go.winto.dev/errors"
+)
+
+// ParallelOptions control how [ParallelMap] and friends run the function.
+//
+// the zero value is valid options, see each field for the default.
+type ParallelOptions struct {
+	// maximum number of function running concurrently, zero or negative mean unlimited.
+	Limit int
+
+	// if false (fail-fast mode), the first error cancel the context passed to the other calls,
+	// no new call is started, and only that error is returned.
+	//
+	// if true (collect-all mode), every item is processed and every error is returned joined in input order.
+	CollectAll bool
+}
+
+// ParallelMap call f for every item in in concurrently, and return the results in the same order as in.
+//
+// if f panic, the panic value is converted into error like [Run2].
+// the result of item that failed or not processed is the zero value of R.
+func ParallelMap[T, R any](ctx context.Context, opts ParallelOptions, in []T, f func(ctx context.Context, v T) (R, error)) ([]R, error) {
+	out := make([]R, len(in))
+	err := parallel(ctx, opts, slices.Values(in), func(ctx context.Context, i int, v T) error {
+		var err error
+		out[i], err = f(ctx, v)
+		return err
+	})
+	return out, err
+}
+
+// ParallelMapSeq is similar to [ParallelMap] but for [iter.Seq].
+//
+// the returned slice only contain results of the items that is pulled from in.
+func ParallelMapSeq[T, R any](ctx context.Context, opts ParallelOptions, in iter.Seq[T], f func(ctx context.Context, v T) (R, error)) ([]R, error) {
+	type slot struct {
+		v T
+		r *R
+	}
+	var slots []*R
+	seq := func(yield func(slot) bool) {
+		for v := range in {
+			s := slot{v, new(R)}
+			slots = append(slots, s.r)
+			if !yield(s) {
+				return
+			}
+		}
+	}
+	err := parallel(ctx, opts, seq, func(ctx context.Context, _ int, s slot) error {
+		var err error
+		*s.r, err = f(ctx, s.v)
+		return err
+	})
+
+	out := make([]R, len(slots))
+	for i, r := range slots {
+		out[i] = *r
+	}
+	return out, err
+}
+
+// ParallelForEach call f for every item in in concurrently, see [ParallelMap].
+func ParallelForEach[T any](ctx context.Context, opts ParallelOptions, in []T, f func(ctx context.Context, v T) error) error {
+	return parallel(ctx, opts, slices.Values(in), func(ctx context.Context, _ int, v T) error { return f(ctx, v) })
+}
+
+// ParallelForEachSeq is similar to [ParallelForEach] but for [iter.Seq].
+func ParallelForEachSeq[T any](ctx context.Context, opts ParallelOptions, in iter.Seq[T], f func(ctx context.Context, v T) error) error {
+	return parallel(ctx, opts, in, func(ctx context.Context, _ int, v T) error { return f(ctx, v) })
+}
+
+func parallel[T any](ctx context.Context, opts ParallelOptions, in iter.Seq[T], f func(ctx context.Context, i int, v T) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var sem *Sem
+	if opts.Limit > 0 {
+		s := NewSem(opts.Limit)
+		sem = &s
+	}
+
+	type indexedErr struct {
+		i   int
+		err error
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []indexedErr
+	var firstErr error
+
+	i, stopped := 0, false
+	for v := range in {
+		if ctx.Err() != nil {
+			stopped = true
+			break
+		}
+		if sem != nil {
+			if sem.Acquire(ctx, 1) != nil {
+				stopped = true
+				break
+			}
+		}
+
+		idx := i
+		i++
+		spawned := errors.CaptureSpawn()
+		wg.Go(func() {
+			if sem != nil {
+				defer sem.Release(1)
+			}
+
+			err := spawned(errors.Catch(func() error { return f(ctx, idx, v) }))
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if opts.CollectAll {
+				errs = append(errs, indexedErr{idx, err})
+			} else if firstErr == nil {
+				firstErr = err
+				cancel(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	var ctxErr error
+	if stopped {
+		ctxErr = context.Cause(ctx)
+	}
+	if len(errs) == 0 {
+		return errors.Trace(ctxErr)
+	}
+
+	slices.SortFunc(errs, func(a, b indexedErr) int { return cmp.Compare(a.i, b.i) })
+	joined := make([]error, 0, len(errs)+1)
+	for _, e := range errs {
+		joined = append(joined, e.err)
+	}
+	if ctxErr != nil {
+		joined = append(joined, ctxErr)
+	}
+	return joinErrs(joined)
+}
@@ -0,0 +1,147 @@
+package async_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+	"go.winto.dev/errors"
+)
+
+type parallelMapFunc func(ctx context.Context, opts async.ParallelOptions, in []int, f func(ctx context.Context, v int) (int, error)) ([]int, error)
+
+var parallelMapVariants = map[string]parallelMapFunc{
+	"slice": async.ParallelMap[int, int],
+	"seq": func(ctx context.Context, opts async.ParallelOptions, in []int, f func(ctx context.Context, v int) (int, error)) ([]int, error) {
+		return async.ParallelMapSeq(ctx, opts, slices.Values(in), f)
+	},
+	"foreach": func(ctx context.Context, opts async.ParallelOptions, in []int, f func(ctx context.Context, v int) (int, error)) ([]int, error) {
+		out := make([]int, len(in))
+		err := async.ParallelForEach(ctx, opts, in, func(ctx context.Context, v int) error {
+			var err error
+			out[v], err = f(ctx, v)
+			return err
+		})
+		return out, err
+	},
+	"foreachseq": func(ctx context.Context, opts async.ParallelOptions, in []int, f func(ctx context.Context, v int) (int, error)) ([]int, error) {
+		out := make([]int, len(in))
+		err := async.ParallelForEachSeq(ctx, opts, slices.Values(in), func(ctx context.Context, v int) error {
+			var err error
+			out[v], err = f(ctx, v)
+			return err
+		})
+		return out, err
+	},
+}
+
+func TestParallelOrder(t *testing.T) {
+	in := make([]int, 20)
+	for i := range in {
+		in[i] = i
+	}
+
+	for name, parallelMap := range parallelMapVariants {
+		for _, limit := range []int{0, 3} {
+			var c concurrency
+			out, err := parallelMap(context.Background(), async.ParallelOptions{Limit: limit}, in, func(ctx context.Context, v int) (int, error) {
+				c.enter()
+				defer c.exit()
+				time.Sleep(time.Duration(len(in)-v) * 100 * time.Microsecond)
+				return v * 10, nil
+			})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for i, v := range out {
+				if v != i*10 {
+					t.Fatalf("%s: results should be in input order, got %v", name, out)
+				}
+			}
+			if limit > 0 && c.max.Load() > int32(limit) {
+				t.Fatalf("%s: at most %d calls should run concurrently, got %d", name, limit, c.max.Load())
+			}
+		}
+	}
+}
+
+func TestParallelFailFast(t *testing.T) {
+	errTest := errors.New("testerr")
+	for name, parallelMap := range parallelMapVariants {
+		var calls atomic.Int32
+		_, err := parallelMap(context.Background(), async.ParallelOptions{Limit: 1}, []int{0, 1, 2, 3}, func(ctx context.Context, v int) (int, error) {
+			calls.Add(1)
+			if v == 1 {
+				return 0, errTest
+			}
+			return v, nil
+		})
+		if err != errTest {
+			t.Fatalf("%s: fail-fast should return only the first error, got %v", name, err)
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("%s: fail-fast should not start new call after the first error, got %d calls", name, calls.Load())
+		}
+	}
+}
+
+func TestParallelCollectAll(t *testing.T) {
+	for name, parallelMap := range parallelMapVariants {
+		out, err := parallelMap(context.Background(), async.ParallelOptions{CollectAll: true}, []int{0, 1, 2, 3}, func(ctx context.Context, v int) (int, error) {
+			if v%2 == 0 {
+				// the later item fail first, but the errors are still in input order
+				time.Sleep(time.Duration(4-v) * time.Millisecond)
+				return 0, fmt.Errorf("err%d", v)
+			}
+			return v, nil
+		})
+		if err == nil || err.Error() != "err0\nerr2" {
+			t.Fatalf("%s: collect-all should return every error in input order, got %v", name, err)
+		}
+		if out[1] != 1 || out[3] != 3 {
+			t.Fatalf("%s: collect-all should process every item, got %v", name, out)
+		}
+	}
+}
+
+func TestParallelPanic(t *testing.T) {
+	for name, parallelMap := range parallelMapVariants {
+		_, err := parallelMap(context.Background(), async.ParallelOptions{}, []int{0, 1}, func(ctx context.Context, v int) (int, error) {
+			if v == 1 {
+				panic("testpanic")
+			}
+			return v, nil
+		})
+		if err == nil || !strings.Contains(err.Error(), "testpanic") || errors.StackTrace(err) == nil {
+			t.Fatalf("%s: panic should be converted into traced error, got %v", name, err)
+		}
+	}
+}
+
+func TestParallelMapSeqPartial(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if i == 3 {
+				cancel()
+			}
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	out, err := async.ParallelMapSeq(ctx, async.ParallelOptions{}, seq, func(ctx context.Context, v int) (int, error) { return v, nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParallelMapSeq should return the context error when stopped early, got %v", err)
+	}
+	// the item pulled after the context is done is not processed
+	if !slices.Equal(out, []int{0, 1, 2, 0}) {
+		t.Fatalf("ParallelMapSeq should only return results of pulled items, got %v", out)
+	}
+}
@@ -0,0 +1,202 @@
+package async
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.winto.dev/errors"
+)
+
+// RunStatus is the status of the function run by [Ticker], [Debouncer] or [Throttler].
+type RunStatus struct {
+	Runs        int       // number of completed runs
+	LastRun     time.Time // when the last run completed, zero if never run
+	LastSuccess time.Time // when the last successful run completed, zero if never succeeded
+	LastError   error     // error returned by the last run, nil if it succeeded
+}
+
+type runStatus struct {
+	mu     sync.Mutex
+	status RunStatus
+}
+
+// run f and record the result, panic is converted into error like [errors.Catch]
+func (s *runStatus) run(ctx context.Context, f func(ctx context.Context) error) {
+	err := errors.Catch(func() error { return f(ctx) })
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Runs++
+	s.status.LastRun = now
+	s.status.LastError = err
+	if err == nil {
+		s.status.LastSuccess = now
+	}
+}
+
+func (s *runStatus) get() RunStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// sleep for d or until ctx is done, return false if ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// TickerOptions control how [Ticker] schedule the runs.
+type TickerOptions struct {
+	// delay between the end of a run and the start of the next run.
+	Interval time.Duration
+
+	// randomize the interval by reducing it up to this fraction (0.0 to 1.0), zero mean no jitter.
+	Jitter float64
+}
+
+// Ticker call a function periodically, see [NewTicker].
+type Ticker struct {
+	opts   TickerOptions
+	f      func(ctx context.Context) error
+	status runStatus
+}
+
+// NewTicker create new [Ticker] that call f periodically when [Ticker.Run] is called.
+//
+// runs never overlap, as the interval is counted from the end of the previous run.
+func NewTicker(opts TickerOptions, f func(ctx context.Context) error) *Ticker {
+	return &Ticker{opts: opts, f: f}
+}
+
+// Run call the function immediately and then every interval, until ctx is done.
+//
+// the error returned by the function (or its panic value) is recorded in [Ticker.Status],
+// it does not stop the ticker. Run always return ctx.Err().
+func (t *Ticker) Run(ctx context.Context) error {
+	for {
+		t.status.run(ctx, t.f)
+
+		interval := t.opts.Interval
+		if t.opts.Jitter > 0 {
+			interval -= time.Duration(float64(interval) * min(t.opts.Jitter, 1) * rand.Float64())
+		}
+		if !sleepCtx(ctx, interval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Status return the status of the runs.
+func (t *Ticker) Status() RunStatus { return t.status.get() }
+
+// Debouncer coalesce bursts of [Debouncer.Trigger] into one call, see [NewDebouncer].
+type Debouncer struct {
+	wait    time.Duration
+	f       func(ctx context.Context) error
+	trigger chan struct{}
+	status  runStatus
+}
+
+// NewDebouncer create new [Debouncer] that call f once there is no [Debouncer.Trigger] for wait duration.
+//
+// f is only called while [Debouncer.Run] is running, and runs never overlap,
+// trigger while f is running schedule another call after it returned.
+func NewDebouncer(wait time.Duration, f func(ctx context.Context) error) *Debouncer {
+	return &Debouncer{wait: wait, f: f, trigger: make(chan struct{}, 1)}
+}
+
+// Trigger schedule a call, it never block.
+func (d *Debouncer) Trigger() {
+	select {
+	case d.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run process the triggers until ctx is done, pending call is dropped when ctx is done.
+//
+// the error returned by the function (or its panic value) is recorded in [Debouncer.Status].
+// Run always return ctx.Err().
+func (d *Debouncer) Run(ctx context.Context) error {
+	for {
+		if _, ok := RecvCtx(ctx, d.trigger); !ok {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(d.wait)
+		for quiet := false; !quiet; {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-d.trigger:
+				timer.Reset(d.wait)
+			case <-timer.C:
+				quiet = true
+			}
+		}
+
+		d.status.run(ctx, d.f)
+	}
+}
+
+// Status return the status of the runs.
+func (d *Debouncer) Status() RunStatus { return d.status.get() }
+
+// Throttler coalesce bursts of [Throttler.Trigger] so the function is called at most once every interval, see [NewThrottler].
+type Throttler struct {
+	interval time.Duration
+	f        func(ctx context.Context) error
+	trigger  chan struct{}
+	status   runStatus
+}
+
+// NewThrottler create new [Throttler] that call f immediately on [Throttler.Trigger],
+// but at most once every interval, triggers in between are coalesced into one call at the end of the interval.
+//
+// f is only called while [Throttler.Run] is running, and runs never overlap.
+func NewThrottler(interval time.Duration, f func(ctx context.Context) error) *Throttler {
+	return &Throttler{interval: interval, f: f, trigger: make(chan struct{}, 1)}
+}
+
+// Trigger schedule a call, it never block.
+func (t *Throttler) Trigger() {
+	select {
+	case t.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run process the triggers until ctx is done, pending call is dropped when ctx is done.
+//
+// the error returned by the function (or its panic value) is recorded in [Throttler.Status].
+// Run always return ctx.Err().
+func (t *Throttler) Run(ctx context.Context) error {
+	for {
+		if _, ok := RecvCtx(ctx, t.trigger); !ok {
+			return ctx.Err()
+		}
+
+		start := time.Now()
+		t.status.run(ctx, t.f)
+		if !sleepCtx(ctx, time.Until(start.Add(t.interval))) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Status return the status of the runs.
+func (t *Throttler) Status() RunStatus { return t.status.get() }
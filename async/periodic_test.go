@@ -0,0 +1,113 @@
+package async_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+)
+
+func TestTickerNoOverlap(t *testing.T) {
+	var c concurrency
+	var runs atomic.Int32
+	ticker := async.NewTicker(async.TickerOptions{Interval: time.Millisecond, Jitter: 0.5}, func(ctx context.Context) error {
+		c.enter()
+		defer c.exit()
+		runs.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := ticker.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Ticker.Run should return ctx.Err(), got %v", err)
+	}
+
+	if runs.Load() < 2 || c.max.Load() != 1 {
+		t.Fatalf("Ticker should run repeatedly without overlap, got %d runs, %d concurrent", runs.Load(), c.max.Load())
+	}
+	if status := ticker.Status(); status.Runs != int(runs.Load()) || status.LastSuccess.IsZero() || status.LastError != nil {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestTickerPanic(t *testing.T) {
+	ticker := async.NewTicker(async.TickerOptions{Interval: time.Hour}, func(ctx context.Context) error { panic("testpanic") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ticker.Run(ctx)
+		close(done)
+	}()
+	eventually(t, "Ticker should run immediately", func() bool { return ticker.Status().Runs == 1 })
+	cancel()
+	<-done
+
+	status := ticker.Status()
+	if status.LastError == nil || !strings.Contains(status.LastError.Error(), "testpanic") || !status.LastSuccess.IsZero() {
+		t.Fatalf("panic should be recorded in status, got %+v", status)
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	var runs atomic.Int32
+	d := async.NewDebouncer(50*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	for range 5 {
+		d.Trigger()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if runs.Load() != 0 {
+		t.Fatalf("Debouncer should wait until the triggers are quiet")
+	}
+	eventually(t, "Debouncer should coalesce the burst into one call", func() bool { return runs.Load() == 1 })
+
+	time.Sleep(80 * time.Millisecond)
+	if runs.Load() != 1 || d.Status().Runs != 1 {
+		t.Fatalf("Debouncer should not call again without trigger")
+	}
+
+	d.Trigger()
+	eventually(t, "Debouncer should call again after new trigger", func() bool { return runs.Load() == 2 })
+}
+
+func TestThrottler(t *testing.T) {
+	var runs atomic.Int32
+	throttler := async.NewThrottler(50*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go throttler.Run(ctx)
+
+	throttler.Trigger()
+	eventually(t, "Throttler should call immediately", func() bool { return runs.Load() == 1 })
+
+	for range 5 {
+		throttler.Trigger()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != 1 {
+		t.Fatalf("Throttler should call at most once every interval")
+	}
+	eventually(t, "Throttler should coalesce triggers into one call at the end of the interval", func() bool { return runs.Load() == 2 })
+
+	time.Sleep(80 * time.Millisecond)
+	if runs.Load() != 2 || throttler.Status().Runs != 2 {
+		t.Fatalf("Throttler should not call again without trigger, got %d", runs.Load())
+	}
+}
@@ -0,0 +1,159 @@
+package async
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// ChanFromSeq return chan that receive every value yielded by seq, it is the reverse of [ChanCtx].
+//
+// the returned chan is closed when seq is exhausted or ctx is done, seq is stopped when ctx is done.
+func ChanFromSeq[T any](ctx context.Context, seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for value := range seq {
+			if !SendCtx(ctx, out, value) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Merge return chan that receive every value from every chs (fan-in), in no particular order.
+//
+// the returned chan is closed when every chs is closed or ctx is done.
+func Merge[T any](ctx context.Context, chs ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, ch := range chs {
+		wg.Go(func() {
+			for value := range ChanCtx(ctx, ch) {
+				if !SendCtx(ctx, out, value) {
+					return
+				}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Broadcast return n chans, every value from ch is sent to every returned chans (fan-out).
+//
+// the next value is not received from ch until the current value is received by every returned chans,
+// so one slow receiver slow down the others.
+// the returned chans are closed when ch is closed or ctx is done.
+func Broadcast[T any](ctx context.Context, ch <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	ret := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		ret[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		for value := range ChanCtx(ctx, ch) {
+			var wg sync.WaitGroup
+			for _, out := range outs {
+				wg.Go(func() { SendCtx(ctx, out, value) })
+			}
+			wg.Wait()
+		}
+	}()
+	return ret
+}
+
+// Batch return chan that receive values from ch grouped into slice of at most size values.
+//
+// the batch is sent when it is full, or when window is elapsed since the first value of the batch is received,
+// zero or negative window mean the batch is only sent when it is full.
+// the last partial batch is sent when ch is closed, and dropped when ctx is done.
+// the returned chan is closed when ch is closed or ctx is done.
+func Batch[T any](ctx context.Context, ch <-chan T, size int, window time.Duration) <-chan []T {
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+
+		var batch []T
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			if len(batch) == 0 {
+				return true
+			}
+			ok := SendCtx(ctx, out, batch)
+			batch = nil
+			return ok
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timeout:
+				if !flush() {
+					return
+				}
+			case value, ok := <-ch:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, value)
+				if len(batch) == 1 && window > 0 {
+					timer = time.NewTimer(window)
+					timeout = timer.C
+				}
+				if len(batch) >= size && !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Throttle return chan that receive every value from ch, but at most one value every interval.
+//
+// values are not dropped, the sender to ch is blocked instead.
+// the returned chan is closed when ch is closed or ctx is done.
+func Throttle[T any](ctx context.Context, ch <-chan T, interval time.Duration) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		var last time.Time
+		for value := range ChanCtx(ctx, ch) {
+			if wait := time.Until(last.Add(interval)); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			if !SendCtx(ctx, out, value) {
+				return
+			}
+			last = time.Now()
+		}
+	}()
+	return out
+}
@@ -0,0 +1,118 @@
+package async_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+)
+
+// collect every value from ch until it is closed, or fail the test after a while
+func collect[T any](t *testing.T, ch <-chan T) []T {
+	t.Helper()
+	var ret []T
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return ret
+			}
+			ret = append(ret, v)
+		case <-timeout:
+			t.Fatalf("chan should be closed")
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	a := async.ChanFromSeq(ctx, slices.Values([]int{1, 2, 3}))
+	b := async.ChanFromSeq(ctx, slices.Values([]int{4, 5}))
+
+	got := collect(t, async.Merge(ctx, a, b))
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("Merge should receive every value, got %v", got)
+	}
+
+	// closed when ctx is done, even if the input is never closed
+	ctx, cancel := context.WithCancel(context.Background())
+	merged := async.Merge(ctx, make(chan int))
+	cancel()
+	collect(t, merged)
+}
+
+func TestBroadcast(t *testing.T) {
+	ctx := context.Background()
+	outs := async.Broadcast(ctx, async.ChanFromSeq(ctx, slices.Values([]int{1, 2, 3})), 2)
+
+	results := make(chan []int, 2)
+	for _, out := range outs {
+		go func() { results <- collect(t, out) }()
+	}
+	for range outs {
+		if got := <-results; !slices.Equal(got, []int{1, 2, 3}) {
+			t.Fatalf("every chan should receive every value, got %v", got)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outs = async.Broadcast(ctx, make(chan int), 2)
+	cancel()
+	for _, out := range outs {
+		collect(t, out)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+
+	// by size, and the last partial batch on close
+	got := collect(t, async.Batch(ctx, async.ChanFromSeq(ctx, slices.Values([]int{1, 2, 3, 4, 5})), 2, 0))
+	if fmt.Sprint(got) != "[[1 2] [3 4] [5]]" {
+		t.Fatalf("Batch should flush by size and on close, got %v", got)
+	}
+
+	// by window
+	ch := make(chan int)
+	batches := async.Batch(ctx, ch, 10, 10*time.Millisecond)
+	ch <- 1
+	ch <- 2
+	select {
+	case batch := <-batches:
+		if !slices.Equal(batch, []int{1, 2}) {
+			t.Fatalf("unexpected batch %v", batch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Batch should flush when the window is elapsed")
+	}
+	ch <- 3
+	close(ch)
+	if got := collect(t, batches); fmt.Sprint(got) != "[[3]]" {
+		t.Fatalf("Batch should flush on close, got %v", got)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+	interval := 10 * time.Millisecond
+	out := async.Throttle(ctx, async.ChanFromSeq(ctx, slices.Values([]int{1, 2, 3, 4})), interval)
+
+	var last time.Time
+	var got []int
+	for v := range out {
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) < interval/2 {
+			t.Fatalf("values should be about %s apart, got %s", interval, now.Sub(last))
+		}
+		last = now
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Fatalf("Throttle should not drop values, got %v", got)
+	}
+}
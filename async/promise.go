@@ -0,0 +1,151 @@
+package async
+
+import (
+	"context"
+	"sync"
+
+	"go.winto.dev/errors"
+)
+
+// PromiseCtx is similar to [Promise] but f receive a context,
+// the context is cancelled when every function waiting for the result gave up (their context is done)
+// before f returned, so abandoned promise stop consuming resources.
+//
+// once the context is cancelled, the result is whatever f returned, usually the context error.
+func PromiseCtx[R any](f func(ctx context.Context) (R, error)) func(context.Context) (R, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var data Result[R]
+
+	spawned := errors.CaptureSpawn()
+	go func() {
+		defer close(done)
+		defer cancel()
+		r, err := errors.Catch2(func() (R, error) { return f(ctx) })
+		data = Result[R]{r, spawned(err)}
+	}()
+
+	var mu sync.Mutex
+	waiters := 0
+	return func(wctx context.Context) (ret R, err error) {
+		// fast path check
+		select {
+		case <-done:
+			return data.Result, data.Error
+		default:
+		}
+
+		mu.Lock()
+		waiters++
+		mu.Unlock()
+
+		select {
+		case <-done:
+			mu.Lock()
+			waiters--
+			mu.Unlock()
+			return data.Result, data.Error
+		case <-wctx.Done():
+			mu.Lock()
+			waiters--
+			if waiters == 0 {
+				cancel()
+			}
+			mu.Unlock()
+			return ret, wctx.Err()
+		}
+	}
+}
+
+type indexedResult[R any] struct {
+	i int
+	Result[R]
+}
+
+// wait every promise concurrently, the returned chan receive every result in the order they settled
+func settle[R any](ctx context.Context, ps []func(context.Context) (R, error)) <-chan indexedResult[R] {
+	ch := make(chan indexedResult[R], len(ps))
+	for i, p := range ps {
+		go func() {
+			r, err := p(ctx)
+			ch <- indexedResult[R]{i, Result[R]{r, err}}
+		}()
+	}
+	return ch
+}
+
+// All return promise that fulfilled with results of every ps in the same order,
+// or rejected with the first error returned by any of ps.
+//
+// the returned promise is created with [PromiseCtx], so it stop waiting ps when every waiter gave up.
+func All[R any](ps ...func(context.Context) (R, error)) func(context.Context) ([]R, error) {
+	return PromiseCtx(func(ctx context.Context) ([]R, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		out := make([]R, len(ps))
+		ch := settle(ctx, ps)
+		for range ps {
+			res := <-ch
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			out[res.i] = res.Result.Result
+		}
+		return out, nil
+	})
+}
+
+// Any return promise that fulfilled with the first successful result of ps,
+// or rejected with every error returned by ps joined in the same order as ps.
+//
+// the returned promise is created with [PromiseCtx], so it stop waiting ps when every waiter gave up.
+func Any[R any](ps ...func(context.Context) (R, error)) func(context.Context) (R, error) {
+	return PromiseCtx(func(ctx context.Context) (ret R, err error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		errs := make([]error, len(ps))
+		ch := settle(ctx, ps)
+		for range ps {
+			res := <-ch
+			if res.Error == nil {
+				return res.Result.Result, nil
+			}
+			errs[res.i] = res.Error
+		}
+		if len(ps) == 0 {
+			return ret, errors.New("async.Any: no promise given")
+		}
+		return ret, errors.Join(errs...)
+	})
+}
+
+// Race return promise that settled with the first settled promise in ps, regardless whether it is successful or not.
+//
+// if ps is empty, the returned promise never settle.
+func Race[R any](ps ...func(context.Context) (R, error)) func(context.Context) (R, error) {
+	return PromiseCtx(func(ctx context.Context) (ret R, err error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		select {
+		case res := <-settle(ctx, ps):
+			return res.Result.Result, res.Error
+		case <-ctx.Done():
+			return ret, ctx.Err()
+		}
+	})
+}
+
+// Then return promise that fulfilled with the result of f applied to the result of p,
+// f is not called if p rejected.
+func Then[R, S any](p func(context.Context) (R, error), f func(ctx context.Context, r R) (S, error)) func(context.Context) (S, error) {
+	return PromiseCtx(func(ctx context.Context) (ret S, err error) {
+		r, err := p(ctx)
+		if err != nil {
+			return ret, err
+		}
+		return f(ctx, r)
+	})
+}
@@ -0,0 +1,135 @@
+package async_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+	"go.winto.dev/errors"
+)
+
+func resolved[R any](r R, err error, delay time.Duration) func(context.Context) (R, error) {
+	return async.PromiseCtx(func(ctx context.Context) (R, error) {
+		time.Sleep(delay)
+		return r, err
+	})
+}
+
+func TestPromiseCtxCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	p := async.PromiseCtx(func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	})
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() { _, err := p(ctx1); errs <- err }()
+	go func() { _, err := p(ctx2); errs <- err }()
+	time.Sleep(20 * time.Millisecond) // let both waiters start waiting
+
+	cancel1()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("waiter should give up with its context error, got %v", err)
+	}
+	select {
+	case <-cancelled:
+		t.Fatalf("producer should not be cancelled while there is still a waiter")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel2()
+	<-errs
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("producer should be cancelled once every waiter gave up")
+	}
+}
+
+func TestPromiseCtxShared(t *testing.T) {
+	calls := 0
+	p := async.PromiseCtx(func(ctx context.Context) (int, error) {
+		calls++
+		return 10, nil
+	})
+	for range 3 {
+		if r, err := p(context.Background()); r != 10 || err != nil {
+			t.Fatalf("unexpected result: %v %v", r, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("f should be called once")
+	}
+}
+
+func TestAll(t *testing.T) {
+	r, err := async.All(resolved(1, nil, 5*time.Millisecond), resolved(2, nil, 0))(context.Background())
+	if err != nil || !slices.Equal(r, []int{1, 2}) {
+		t.Fatalf("All should return the results in the same order, got %v %v", r, err)
+	}
+
+	errTest := errors.New("testerr")
+	_, err = async.All(resolved(1, nil, time.Hour), resolved(0, errTest, 0))(context.Background())
+	if err != errTest {
+		t.Fatalf("All should reject with the first error without waiting the others, got %v", err)
+	}
+
+	r, err = async.All[int]()(context.Background())
+	if err != nil || len(r) != 0 {
+		t.Fatalf("All without promise should fulfilled with empty result")
+	}
+}
+
+func TestAny(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	r, err := async.Any(resolved(0, errA, 0), resolved(2, nil, 5*time.Millisecond), resolved(3, nil, time.Hour))(context.Background())
+	if err != nil || r != 2 {
+		t.Fatalf("Any should return the first successful result, got %v %v", r, err)
+	}
+
+	_, err = async.Any(resolved(0, errA, 5*time.Millisecond), resolved(0, errB, 0))(context.Background())
+	if err == nil || err.Error() != "a\nb" || !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Any should join every error in the same order, got %v", err)
+	}
+
+	if _, err = async.Any[int]()(context.Background()); err == nil {
+		t.Fatalf("Any without promise should be rejected")
+	}
+}
+
+func TestRace(t *testing.T) {
+	errTest := errors.New("testerr")
+	_, err := async.Race(resolved(1, nil, time.Hour), resolved(0, errTest, 0))(context.Background())
+	if err != errTest {
+		t.Fatalf("Race should settle with the first settled promise, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = async.Race[int]()(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Race without promise should never settle, got %v", err)
+	}
+}
+
+func TestThen(t *testing.T) {
+	double := func(ctx context.Context, r int) (int, error) { return r * 2, nil }
+	r, err := async.Then(resolved(2, nil, 0), double)(context.Background())
+	if err != nil || r != 4 {
+		t.Fatalf("Then should apply f to the result, got %v %v", r, err)
+	}
+
+	errTest := errors.New("testerr")
+	called := false
+	_, err = async.Then(resolved(0, errTest, 0), func(ctx context.Context, r int) (int, error) {
+		called = true
+		return r, nil
+	})(context.Background())
+	if err != errTest || called {
+		t.Fatalf("Then should not call f when p rejected")
+	}
+}
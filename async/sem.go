@@ -0,0 +1,184 @@
+package async
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"go.winto.dev/errors"
+)
+
+// Sem is weighted semaphore, the zero value is not usable, use [NewSem] to create it.
+//
+// waiters are served in FIFO order, so large request is not starved by smaller ones.
+// copy of Sem share the same state.
+type Sem struct{ s *semState }
+
+type semState struct {
+	mu      sync.Mutex
+	size    int
+	cur     int
+	waiters list.List // of *semWaiter
+}
+
+type semWaiter struct {
+	n     int
+	ready chan struct{}
+}
+
+func NewSem(size int) Sem {
+	return Sem{&semState{size: size}}
+}
+
+// Acquire n units of the semaphore, blocking until they are available or ctx is done.
+//
+// on failure, ctx.Err() is returned and the semaphore is left unchanged.
+// error is returned immediately if n exceed the size of the semaphore, as it can never be acquired.
+func (s Sem) Acquire(ctx context.Context, n int) error {
+	st := s.s
+	st.mu.Lock()
+	if n > st.size {
+		size := st.size
+		st.mu.Unlock()
+		return errors.Errorf("async: cannot acquire %d units of Sem with size %d", n, size)
+	}
+	if !st.queuedLocked() && st.cur+n <= st.size {
+		st.cur += n
+		st.mu.Unlock()
+		return nil
+	}
+
+	w := &semWaiter{n, make(chan struct{})}
+	elem := st.waiters.PushBack(w)
+	st.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	select {
+	case <-w.ready:
+		// acquired after ctx is done, put it back
+		st.cur -= n
+	default:
+		st.waiters.Remove(elem)
+	}
+	st.notifyLocked()
+	return ctx.Err()
+}
+
+// TryAcquire is similar to [Sem.Acquire] but never block, it return false if the units are not available.
+func (s Sem) TryAcquire(n int) bool {
+	st := s.s
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.queuedLocked() && st.cur+n <= st.size {
+		st.cur += n
+		return true
+	}
+	return false
+}
+
+// Release n units of the semaphore, n must not exceed the units currently held.
+func (s Sem) Release(n int) {
+	st := s.s
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.cur -= n
+	if st.cur < 0 {
+		panic("async: Sem released more than held")
+	}
+	st.notifyLocked()
+}
+
+// Resize change the size of the semaphore.
+//
+// shrinking does not affect the units already held, new request wait until enough units are released.
+// waiting request that exceed the new size keep waiting until the semaphore is grown again,
+// but it doesn't block the waiters behind it.
+func (s Sem) Resize(size int) {
+	st := s.s
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.size = size
+	st.notifyLocked()
+}
+
+// Size return the current size of the semaphore.
+func (s Sem) Size() int {
+	st := s.s
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.size
+}
+
+// InUse return the number of units currently held.
+func (s Sem) InUse() int {
+	st := s.s
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.cur
+}
+
+// Waiting return the number of [Sem.Acquire] call currently waiting.
+func (s Sem) Waiting() int {
+	st := s.s
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.waiters.Len()
+}
+
+// report whether there is waiter that can be served, new request must not jump ahead of it
+func (st *semState) queuedLocked() bool {
+	for elem := st.waiters.Front(); elem != nil; elem = elem.Next() {
+		if elem.Value.(*semWaiter).n <= st.size {
+			return true
+		}
+	}
+	return false
+}
+
+func (st *semState) notifyLocked() {
+	for elem := st.waiters.Front(); elem != nil; {
+		w := elem.Value.(*semWaiter)
+		if w.n > st.size {
+			// cannot be served until the semaphore is grown, see Resize
+			elem = elem.Next()
+			continue
+		}
+		if st.cur+w.n > st.size {
+			// do not let smaller request jump ahead of the front waiter
+			return
+		}
+		st.cur += w.n
+		next := elem.Next()
+		st.waiters.Remove(elem)
+		close(w.ready)
+		elem = next
+	}
+}
+
+// Run runs a function with semaphore control.
+func (s Sem) Run(ctx context.Context, f func(ctx context.Context) error) error {
+	if err := s.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	defer s.Release(1)
+	return f(ctx)
+}
+
+// RunNoPanic is similar to [Sem.Run] but assuming f will not panic.
+//
+// if f panic, the semaphore count will not be restored.
+func (s Sem) RunNoPanic(ctx context.Context, f func(ctx context.Context) error) error {
+	if err := s.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	err := f(ctx)
+	s.Release(1)
+	return err
+}
@@ -0,0 +1,133 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+)
+
+func TestSemFIFO(t *testing.T) {
+	sem := async.NewSem(3)
+	ctx := context.Background()
+	if err := sem.Acquire(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make([]chan struct{}, 3)
+	for i, n := range []int{2, 1, 1} {
+		acquired[i] = make(chan struct{})
+		go func() {
+			sem.Acquire(ctx, n)
+			close(acquired[i])
+		}()
+		eventually(t, "waiter should be queued", func() bool { return sem.Waiting() == i+1 })
+	}
+	isAcquired := func(i int) bool {
+		select {
+		case <-acquired[i]:
+			return true
+		case <-time.After(20 * time.Millisecond):
+			return false
+		}
+	}
+
+	if sem.TryAcquire(1) {
+		t.Fatal("Sem.TryAcquire should not jump ahead of waiters")
+	}
+
+	sem.Release(1)
+	if isAcquired(1) || isAcquired(2) {
+		t.Fatal("smaller waiter should not jump ahead of the front waiter")
+	}
+
+	sem.Release(2)
+	if !isAcquired(0) || !isAcquired(1) || isAcquired(2) || sem.InUse() != 3 {
+		t.Fatal("waiters should be served in FIFO order")
+	}
+
+	sem.Release(2)
+	if !isAcquired(2) || sem.InUse() != 2 {
+		t.Fatal("the last waiter should be served after the front waiter released")
+	}
+}
+
+func TestSemCancel(t *testing.T) {
+	sem := async.NewSem(2)
+	sem.Acquire(context.Background(), 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("Sem.Acquire should return ctx.Err(), got %v", err)
+	}
+	if sem.Waiting() != 0 || sem.InUse() != 2 {
+		t.Fatalf("cancelled Sem.Acquire should leave the semaphore unchanged")
+	}
+
+	sem.Release(2)
+	if sem.InUse() != 0 || !sem.TryAcquire(2) {
+		t.Fatalf("released units should be available again")
+	}
+}
+
+func TestSemResize(t *testing.T) {
+	sem := async.NewSem(1)
+	ctx := context.Background()
+	sem.Acquire(ctx, 1)
+
+	done := make(chan struct{})
+	go func() {
+		sem.Acquire(ctx, 1)
+		close(done)
+	}()
+	eventually(t, "waiter should be queued", func() bool { return sem.Waiting() == 1 })
+
+	sem.Resize(2)
+	<-done
+	if sem.Size() != 2 || sem.InUse() != 2 {
+		t.Fatalf("Sem.Resize should serve the waiters")
+	}
+
+	sem.Resize(1)
+	sem.Release(1)
+	if sem.TryAcquire(1) {
+		t.Fatalf("shrunk Sem should not exceed the new size")
+	}
+	sem.Release(1)
+	if !sem.TryAcquire(1) {
+		t.Fatalf("Sem.TryAcquire should succeed once enough units are released")
+	}
+}
+
+func TestSemTooLarge(t *testing.T) {
+	sem := async.NewSem(2)
+	if err := sem.Acquire(context.Background(), 3); err == nil {
+		t.Fatal("Sem.Acquire should fail when n exceed the size")
+	}
+	if sem.TryAcquire(3) {
+		t.Fatal("Sem.TryAcquire should fail when n exceed the size")
+	}
+
+	// waiter that exceed the size after shrinking should not block the others
+	sem.Acquire(context.Background(), 2)
+	large := make(chan struct{})
+	go func() {
+		sem.Acquire(context.Background(), 2)
+		close(large)
+	}()
+	eventually(t, "waiter should be queued", func() bool { return sem.Waiting() == 1 })
+	sem.Resize(1)
+	sem.Release(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sem.Acquire(ctx, 1); err != nil {
+		t.Fatalf("Sem.Acquire should not be blocked by waiter that exceed the size, got %v", err)
+	}
+	sem.Release(1)
+
+	sem.Resize(2)
+	<-large
+}
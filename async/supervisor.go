@@ -0,0 +1,389 @@
+package async
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.winto.dev/errors"
+)
+
+// RestartPolicy decide when a worker is restarted by [Supervisor].
+type RestartPolicy int
+
+const (
+	RestartOnFailure RestartPolicy = iota // restart when the worker return error or panic
+	RestartAlways                         // restart whenever the worker return, even with nil error
+	RestartNever                          // never restart the worker
+)
+
+// SupervisorStrategy decide which workers are restarted when a worker need to be restarted.
+type SupervisorStrategy int
+
+const (
+	OneForOne SupervisorStrategy = iota // only restart the worker that returned
+	OneForAll                           // stop every other worker and restart them all together
+)
+
+// WorkerState is the state of a worker in [Supervisor].
+type WorkerState int
+
+const (
+	WorkerIdle       WorkerState = iota // not started yet
+	WorkerRunning                       // the worker function is running
+	WorkerRestarting                    // waiting for back-off delay before restarted
+	WorkerStopped                       // returned and will not be restarted
+)
+
+func (s WorkerState) String() string {
+	switch s {
+	case WorkerIdle:
+		return "idle"
+	case WorkerRunning:
+		return "running"
+	case WorkerRestarting:
+		return "restarting"
+	case WorkerStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// WorkerStatus is snapshot of a worker status, see [Supervisor.Status].
+type WorkerStatus struct {
+	Name      string
+	State     WorkerState
+	Since     time.Time // when the worker entered the current state
+	Restarts  int       // number of times the worker has been restarted
+	LastError error     // the last error returned by the worker (or its panic value) while it is not cancelled, nil if none
+}
+
+// SupervisorOptions control how [Supervisor] restart the workers.
+//
+// the zero value is valid options, see each field for the default.
+type SupervisorOptions struct {
+	// which workers are restarted, zero mean [OneForOne].
+	Strategy SupervisorStrategy
+
+	// back-off delay before the first restart, zero mean 100ms.
+	InitialDelay time.Duration
+
+	// maximum back-off delay, zero mean 30s.
+	// the back-off is reset when the worker (or every worker for [OneForAll]) ran at least this long.
+	MaxDelay time.Duration
+
+	// the back-off delay is multiplied by this value after each consecutive restart, zero mean 2.
+	Multiplier float64
+
+	// randomize the delay by reducing it up to this fraction (0.0 to 1.0), zero mean no jitter.
+	Jitter float64
+
+	// maximum number of restarts within Period, when exceeded, every worker is stopped and [Supervisor.Run] return error.
+	// zero mean 10, negative mean unlimited.
+	MaxRestarts int
+
+	// see MaxRestarts, zero mean 1 minute.
+	Period time.Duration
+
+	// called every time a worker return error or panic, nil mean do nothing.
+	//
+	// error returned after the worker's context is cancelled (on shutdown, or when stopped by [OneForAll]) is not reported,
+	// and not recorded in [WorkerStatus].
+	OnError func(name string, err error)
+}
+
+func (o *SupervisorOptions) maxDelay() time.Duration {
+	if o.MaxDelay <= 0 {
+		return 30 * time.Second
+	}
+	return o.MaxDelay
+}
+
+func (o *SupervisorOptions) delay(failures int) time.Duration {
+	initial, maxDelay, multiplier := o.InitialDelay, o.maxDelay(), o.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	d := float64(initial)
+	for i := 1; i < failures && d < float64(maxDelay); i++ {
+		d *= multiplier
+	}
+	d = min(d, float64(maxDelay))
+
+	if o.Jitter > 0 {
+		d -= d * min(o.Jitter, 1) * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
+
+// Supervisor run long-running workers and restart them when they return, see [NewSupervisor].
+type Supervisor struct {
+	opts SupervisorOptions
+
+	mu       sync.Mutex
+	workers  []*worker
+	running  bool
+	restarts []time.Time // restart times within the period
+	failure  error
+}
+
+type worker struct {
+	name    string
+	restart RestartPolicy
+	f       func(ctx context.Context) error
+	status  WorkerStatus // protected by Supervisor.mu
+}
+
+func (w *worker) shouldRestart(err error) bool {
+	switch w.restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	}
+	return false
+}
+
+// NewSupervisor create new [Supervisor], add the workers with [Supervisor.Add] and then call [Supervisor.Run].
+func NewSupervisor(opts SupervisorOptions) *Supervisor {
+	return &Supervisor{opts: opts}
+}
+
+// Add register new worker, f must return when ctx is done.
+//
+// Add must be called before [Supervisor.Run].
+func (s *Supervisor) Add(name string, restart RestartPolicy, f func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		panic("async: Supervisor.Add called after Supervisor.Run")
+	}
+	s.workers = append(s.workers, &worker{
+		name:    name,
+		restart: restart,
+		f:       f,
+		status:  WorkerStatus{Name: name, State: WorkerIdle, Since: time.Now()},
+	})
+}
+
+// Status return the status of every worker, in the same order as they are added.
+func (s *Supervisor) Status() []WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]WorkerStatus, len(s.workers))
+	for i, w := range s.workers {
+		ret[i] = w.status
+	}
+	return ret
+}
+
+// Run start every worker and supervise them until ctx is done, then wait for every worker to return.
+//
+// ctx is intended to be the context passed by mainpkg.Exec, so the workers are stopped on graceful shutdown.
+//
+// Run return nil when ctx is done or every worker stopped without being restarted,
+// and return error if the maximum restart intensity is exceeded.
+// Run cannot be called twice.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		panic("async: Supervisor.Run called twice")
+	}
+	s.running = true
+	workers := s.workers
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if s.opts.Strategy == OneForAll {
+		s.runOneForAll(ctx, cancel, workers)
+	} else {
+		var wg sync.WaitGroup
+		for _, w := range workers {
+			wg.Go(func() { s.runOneForOne(ctx, cancel, w) })
+		}
+		wg.Wait()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failure
+}
+
+func (s *Supervisor) runOneForOne(ctx context.Context, cancel context.CancelFunc, w *worker) {
+	defer s.setState(w, WorkerStopped)
+
+	failures := 0
+	for {
+		start := time.Now()
+		err := s.runOnce(ctx, w)
+		if ctx.Err() != nil || !w.shouldRestart(err) {
+			return
+		}
+
+		if time.Since(start) >= s.opts.maxDelay() {
+			failures = 0
+		}
+		failures++
+		if !s.recordRestart(cancel, w, err) {
+			return
+		}
+
+		s.setRestarting(w)
+		if !sleepCtx(ctx, s.opts.delay(failures)) {
+			return
+		}
+	}
+}
+
+func (s *Supervisor) runOneForAll(ctx context.Context, cancel context.CancelFunc, workers []*worker) {
+	defer func() {
+		for _, w := range workers {
+			s.setState(w, WorkerStopped)
+		}
+	}()
+
+	type exit struct {
+		w   *worker
+		err error
+	}
+
+	failures := 0
+	active := workers
+	for len(active) > 0 {
+		groupCtx, groupCancel := context.WithCancel(ctx)
+		exits := make(chan exit, len(active))
+		start := time.Now()
+		for _, w := range active {
+			go func() { exits <- exit{w, s.runOnce(groupCtx, w)} }()
+		}
+
+		var trigger *exit
+		var next []*worker
+		stopped := make(map[*worker]bool)
+		for range active {
+			e := <-exits
+			if trigger == nil && ctx.Err() == nil && e.w.shouldRestart(e.err) {
+				trigger = &e
+				groupCancel()
+			}
+			if trigger == nil {
+				// returned on its own and not need to be restarted
+				stopped[e.w] = true
+				s.setState(e.w, WorkerStopped)
+			}
+		}
+		groupCancel()
+
+		if ctx.Err() != nil || trigger == nil {
+			return
+		}
+
+		for _, w := range active {
+			if stopped[w] || (w != trigger.w && w.restart == RestartNever) {
+				s.setState(w, WorkerStopped)
+				continue
+			}
+			next = append(next, w)
+		}
+		active = next
+
+		if time.Since(start) >= s.opts.maxDelay() {
+			failures = 0
+		}
+		failures++
+		if !s.recordRestart(cancel, trigger.w, trigger.err) {
+			return
+		}
+
+		for _, w := range active {
+			s.setRestarting(w)
+		}
+		if !sleepCtx(ctx, s.opts.delay(failures)) {
+			return
+		}
+	}
+}
+
+// run the worker once, panic is converted into error like [errors.Catch]
+func (s *Supervisor) runOnce(ctx context.Context, w *worker) error {
+	s.setState(w, WorkerRunning)
+	err := errors.Catch(func() error { return w.f(ctx) })
+	if err == nil || ctx.Err() != nil {
+		// stopped by the supervisor, the error is most likely just the cancellation
+		return err
+	}
+
+	s.mu.Lock()
+	w.status.LastError = err
+	s.mu.Unlock()
+
+	if s.opts.OnError != nil {
+		s.opts.OnError(w.name, err)
+	}
+	return err
+}
+
+// record the restart of w, return false and stop every worker if the restart intensity is exceeded
+func (s *Supervisor) recordRestart(cancel context.CancelFunc, w *worker, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opts.MaxRestarts < 0 {
+		return true
+	}
+	maxRestarts, period := s.opts.MaxRestarts, s.opts.Period
+	if maxRestarts == 0 {
+		maxRestarts = 10
+	}
+	if period <= 0 {
+		period = time.Minute
+	}
+
+	now := time.Now()
+	i := 0
+	for i < len(s.restarts) && now.Sub(s.restarts[i]) > period {
+		i++
+	}
+	s.restarts = append(s.restarts[i:], now)
+	if len(s.restarts) <= maxRestarts {
+		return true
+	}
+
+	if s.failure == nil {
+		if err == nil {
+			err = errors.New("worker returned")
+		}
+		s.failure = errors.Errorf(
+			"async: supervisor exceeded %d restarts in %s, last restarted worker %q: %w",
+			maxRestarts, period, w.name, err,
+		)
+	}
+	cancel()
+	return false
+}
+
+func (s *Supervisor) setState(w *worker, state WorkerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.status.State != state {
+		w.status.State = state
+		w.status.Since = time.Now()
+	}
+}
+
+func (s *Supervisor) setRestarting(w *worker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.status.State = WorkerRestarting
+	w.status.Since = time.Now()
+	w.status.Restarts++
+}
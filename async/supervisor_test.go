@@ -0,0 +1,203 @@
+package async_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.winto.dev/async"
+	"go.winto.dev/errors"
+)
+
+type reportedErrors struct {
+	mu   sync.Mutex
+	errs []string
+}
+
+func (r *reportedErrors) add(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, name+":"+err.Error())
+}
+
+func (r *reportedErrors) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errs...)
+}
+
+func TestSupervisorOneForOne(t *testing.T) {
+	var reported reportedErrors
+	s := async.NewSupervisor(async.SupervisorOptions{InitialDelay: time.Millisecond, OnError: reported.add})
+
+	var badRuns, goodRuns atomic.Int32
+	s.Add("bad", async.RestartOnFailure, func(ctx context.Context) error {
+		if badRuns.Add(1) <= 2 {
+			return errors.New("testerr")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.Add("good", async.RestartOnFailure, func(ctx context.Context) error {
+		goodRuns.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.Add("once", async.RestartNever, func(ctx context.Context) error { return errors.New("onceerr") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	eventually(t, "bad worker should be restarted twice", func() bool {
+		status := s.Status()
+		return status[0].Restarts == 2 && status[0].State == async.WorkerRunning && status[2].State == async.WorkerStopped
+	})
+
+	status := s.Status()
+	if goodRuns.Load() != 1 || status[1].Restarts != 0 || status[1].State != async.WorkerRunning {
+		t.Fatalf("other worker should not be restarted with OneForOne")
+	}
+	if status[0].LastError == nil || status[2].LastError == nil || status[2].Restarts != 0 {
+		t.Fatalf("unexpected status: %v", status)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Supervisor.Run should return nil on shutdown, got %v", err)
+	}
+	for _, st := range s.Status() {
+		if st.State != async.WorkerStopped {
+			t.Fatalf("every worker should be stopped after Supervisor.Run returned")
+		}
+	}
+
+	errs := reported.get()
+	slices.Sort(errs)
+	if got := strings.Join(errs, ","); got != "bad:testerr,bad:testerr,once:onceerr" {
+		t.Fatalf("OnError should not be called for cancelled worker, got %s", got)
+	}
+}
+
+func TestSupervisorOneForAll(t *testing.T) {
+	var reported reportedErrors
+	s := async.NewSupervisor(async.SupervisorOptions{
+		Strategy:     async.OneForAll,
+		InitialDelay: time.Millisecond,
+		OnError:      reported.add,
+	})
+
+	var badRuns, goodRuns atomic.Int32
+	s.Add("bad", async.RestartOnFailure, func(ctx context.Context) error {
+		if badRuns.Add(1) == 1 {
+			return errors.New("testerr")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.Add("good", async.RestartOnFailure, func(ctx context.Context) error {
+		goodRuns.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	eventually(t, "every worker should be restarted together", func() bool {
+		status := s.Status()
+		return goodRuns.Load() == 2 && status[0].State == async.WorkerRunning && status[1].State == async.WorkerRunning
+	})
+
+	status := s.Status()
+	if status[0].Restarts != 1 || status[1].Restarts != 1 {
+		t.Fatalf("unexpected restarts: %v", status)
+	}
+	if status[1].LastError != nil {
+		t.Fatalf("worker stopped by the supervisor should not record error, got %v", status[1].LastError)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(reported.get(), ","); got != "bad:testerr" {
+		t.Fatalf("OnError should only be called for the failed worker, got %s", got)
+	}
+}
+
+func TestSupervisorBackoffReset(t *testing.T) {
+	// with InitialDelay 20ms, MaxDelay 100ms, and Multiplier 4, the delays are 20ms, 80ms, 100ms, ...
+	// unless the back-off is reset because the worker ran at least MaxDelay
+	run := func(runFor time.Duration) []time.Duration {
+		s := async.NewSupervisor(async.SupervisorOptions{
+			InitialDelay: 20 * time.Millisecond,
+			MaxDelay:     100 * time.Millisecond,
+			Multiplier:   4,
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var gaps []time.Duration
+		var last time.Time
+		s.Add("worker", async.RestartAlways, func(ctx context.Context) error {
+			if !last.IsZero() {
+				gaps = append(gaps, time.Since(last))
+			}
+			if len(gaps) == 3 {
+				cancel()
+			}
+			time.Sleep(runFor)
+			last = time.Now()
+			return nil
+		})
+		s.Run(ctx)
+		return gaps
+	}
+
+	gaps := run(0)
+	if gaps[2] < 60*time.Millisecond {
+		t.Fatalf("the back-off should grow, got %v", gaps)
+	}
+
+	gaps = run(100 * time.Millisecond)
+	for _, gap := range gaps {
+		if gap >= 60*time.Millisecond {
+			t.Fatalf("the back-off should be reset after long run, got %v", gaps)
+		}
+	}
+}
+
+func TestSupervisorMaxRestarts(t *testing.T) {
+	errTest := errors.New("testerr")
+	s := async.NewSupervisor(async.SupervisorOptions{InitialDelay: time.Millisecond, MaxRestarts: 2})
+	s.Add("bad", async.RestartOnFailure, func(ctx context.Context) error { return errTest })
+	s.Add("good", async.RestartOnFailure, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Supervisor.Run should return when the restart intensity is exceeded")
+	}
+
+	if !errors.Is(err, errTest) || !strings.Contains(err.Error(), "exceeded 2 restarts") {
+		t.Fatalf("Supervisor.Run should return the last worker error, got %v", err)
+	}
+	status := s.Status()
+	if status[0].Restarts != 2 || status[0].State != async.WorkerStopped || status[1].State != async.WorkerStopped {
+		t.Fatalf("every worker should be stopped, got %v", status)
+	}
+}
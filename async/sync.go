@@ -1,43 +1,11 @@
 package async
 
 import (
-	"context"
 	"sync"
 
 	"go.winto.dev/errors"
 )
 
-type Sem struct{ ch chan struct{} }
-
-func NewSem(size int) Sem {
-	return Sem{make(chan struct{}, size)}
-}
-
-// Run runs a function with semaphore control.
-func (s Sem) Run(ctx context.Context, f func(ctx context.Context) error) error {
-	select {
-	case s.ch <- struct{}{}:
-	case <-ctx.Done():
-		return ctx.Err()
-	}
-	defer func() { <-s.ch }()
-	return f(ctx)
-}
-
-// RunNoPanic is similar to [Sem.Run] but assuming f will not panic.
-//
-// if f panic, the semaphore count will not be restored.
-func (s Sem) RunNoPanic(ctx context.Context, f func(ctx context.Context) error) error {
-	select {
-	case s.ch <- struct{}{}:
-	case <-ctx.Done():
-		return ctx.Err()
-	}
-	err := f(ctx)
-	<-s.ch
-	return err
-}
-
 type Mutex struct{ sync.Mutex }
 
 // Run runs a function with mutex control.
@@ -132,18 +100,22 @@ func (m *RWMutex) RunEReadNoPanic(f func() error) error {
 type WaitGroup struct{ sync.WaitGroup }
 
 // Run f in new goroutine, and register it into the waitgroup, and return chan to get the value returned by f or the panic value if f panic.
+//
+// if spawn trace is enabled (see [errors.SetSpawnTrace]), the caller locations will be attached to the error.
 func (wg *WaitGroup) Run(f func() error) <-chan error {
 	ch := make(chan error, 1)
-	wg.Go(func() { ch <- errors.Catch(f) })
+	spawned := errors.CaptureSpawn()
+	wg.Go(func() { ch <- spawned(errors.Catch(f)) })
 	return ch
 }
 
 // WaitGroupRun2 similar to [WaitGroup.Run] but also returning other value not just error.
 func WaitGroupRun2[R any](wg *WaitGroup, f func() (R, error)) <-chan Result[R] {
 	ch := make(chan Result[R], 1)
+	spawned := errors.CaptureSpawn()
 	wg.Go(func() {
 		r, err := errors.Catch2(f)
-		ch <- Result[R]{r, err}
+		ch <- Result[R]{r, spawned(err)}
 	})
 	return ch
 }
@@ -16,6 +16,9 @@ type ParseError struct {
 
 var ErrCauseRequired = errors.New("required but not specified")
 
+// the cause of violation of "oneof", "min", "max" or "pattern" tag option, the actual cause wrap this error.
+var ErrCauseInvalid = errors.New("invalid value")
+
 func (p *ParseError) Error() string {
 	points := make([]string, len(p.Items))
 	for i, item := range p.Items {
@@ -38,3 +41,13 @@ func (p *ParseError) append(key, value string, cause error) {
 		Cause: cause,
 	})
 }
+
+// append err returned by [Validator], if err is [*ParseError], its items are appended instead
+func (p *ParseError) appendValidation(key, value string, err error) {
+	var inner *ParseError
+	if errors.As(err, &inner) {
+		p.Items = append(p.Items, inner.Items...)
+		return
+	}
+	p.append(key, value, err)
+}
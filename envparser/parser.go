@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -16,8 +17,26 @@ import (
 // If "env" tag has "nounset" option, the env will be kept, otherwise it will be unset.
 // If "env" tag has "skip" option, the field will be skipped.
 // If "env" tag has "required" option, it will error if the env is not set.
+// If "env" tag has "default=value" option, the value will be used if the env is not set.
+// If "env" tag has "oneof=a|b|c" option, the env must be one of the values separated by "|".
+// If "env" tag has "min=n" or "max=n" option, the numeric (or [time.Duration]) field must be in the range.
+// If "env" tag has "pattern=regexp" option, the env must match the regular expression.
+//
+// the value of "default" and "pattern" option can contain comma,
+// text after the comma that is not a known option is part of the value.
+// every violation is collected into [ParseError].
+//
+// if the field or target implement [Validator] interface, it will be called after the value is set.
 //
 // if the field implement [Unmarshaler] interface, it will be used.
+//
+// nested struct field is read as JSON from its env, if the env is not set,
+// its fields are read recursively with the env name of the struct field as prefix
+// (e.g. field DB with tag `env:"DB_"` read DB_HOST for its Host field with tag `env:"HOST"`).
+// the prefix is used as is, so struct field without name in the "env" tag use the field name without separator
+// (e.g. DBHost for field DB), specify the name with the separator (e.g. `env:"DB_"`) to avoid it.
+// embedded struct without name in the "env" tag is read without additional prefix.
+// "nounset" and "required" option in the struct field apply to all of its fields.
 func Unmarshal(target any) error {
 	return UnmarshalWithPrefix(target, "")
 }
@@ -27,70 +46,142 @@ func UnmarshalWithPrefix(target any, prefix string) error {
 	targetVal := valueOfPointerToStruct(target)
 
 	var parseError ParseError
+	unmarshalStruct(targetVal, prefix, envConfig{}, &parseError)
+	validateStruct(targetVal, prefix, &parseError)
+	if len(parseError.Items) > 0 {
+		return &parseError
+	}
 
+	return nil
+}
+
+// unmarshal every field of targetVal, parent is the config of the field that contain targetVal (if any)
+func unmarshalStruct(targetVal reflect.Value, prefix string, parent envConfig, parseError *ParseError) {
 	for i, t := 0, targetVal.Type(); i < t.NumField(); i++ {
-		envConfig := lookupEnvConfig(targetVal.Type().Field(i))
+		envConfig := lookupEnvConfig(t.Field(i))
 		if envConfig.skip {
 			continue
 		}
+		envConfig.noUnset = envConfig.noUnset || parent.noUnset
+		envConfig.required = envConfig.required || parent.required
+
+		nested, isNested := nestedPrefix(t.Field(i), envConfig)
+		if !t.Field(i).IsExported() {
+			// only embedded struct of unexported type can be filled
+			if isNested {
+				unmarshalStruct(targetVal.Field(i), prefix+nested, envConfig, parseError)
+			}
+			continue
+		}
 
 		key := prefix + envConfig.name
 		val, ok := os.LookupEnv(key)
-		if !ok {
+		if ok {
+			if !envConfig.noUnset {
+				os.Unsetenv(key)
+			}
+		} else if isNested {
+			unmarshalStruct(targetVal.Field(i), prefix+nested, envConfig, parseError)
+			if !t.Field(i).Anonymous {
+				// embedded struct is validated by the outer struct, as the method is promoted
+				validateStruct(targetVal.Field(i), prefix+nested, parseError)
+			}
+			continue
+		} else if envConfig.hasDefault {
+			val = envConfig.defaultValue
+		} else {
 			if envConfig.required {
 				parseError.append(key, "", ErrCauseRequired)
 			}
 			continue
 		}
-		if !envConfig.noUnset {
-			os.Unsetenv(key)
-		}
 
 		f := targetVal.Field(i)
-		if f.Addr().Type().Implements(unmarshalerType) {
-			if err := f.Addr().Interface().(Unmarshaler).UnmarshalEnv(val); err != nil {
-				parseError.append(key, val, err)
-			}
+		if err := checkValue(val, envConfig); err != nil {
+			parseError.append(key, val, err)
 			continue
 		}
-		if f.Kind() == reflect.String {
-			f.SetString(val)
+		if err := setField(f, val); err != nil {
+			parseError.append(key, val, err)
 			continue
 		}
-		fn, ok := nativeUnmarshaler[f.Type()]
-		if ok {
-			v, err := fn(val)
-			if err != nil {
-				parseError.append(key, val, err)
-			} else {
-				f.Set(reflect.ValueOf(v))
-			}
+		if err := checkRange(f, envConfig); err != nil {
+			parseError.append(key, val, err)
 			continue
 		}
-		if err := json.Unmarshal([]byte(val), f.Addr().Interface()); err != nil {
-			if f.Kind() == reflect.Slice {
-				if f.Type().Elem().Kind() == reflect.String {
-					ss := strings.Split(val, ",")
-					for i := range ss {
-						ss[i] = strings.TrimSpace(ss[i])
-					}
-					f.Set(reflect.ValueOf(ss))
-				} else {
-					if err2 := json.Unmarshal([]byte("["+val+"]"), f.Addr().Interface()); err2 != nil {
-						parseError.append(key, val, err) // append first error
-					}
+		if v, ok := f.Addr().Interface().(Validator); ok {
+			if err := v.Validate(); err != nil {
+				parseError.appendValidation(key, val, err)
+			}
+		}
+	}
+}
+
+func setField(f reflect.Value, val string) error {
+	if f.Addr().Type().Implements(unmarshalerType) {
+		return f.Addr().Interface().(Unmarshaler).UnmarshalEnv(val)
+	}
+	if f.Kind() == reflect.String {
+		f.SetString(val)
+		return nil
+	}
+	fn, ok := nativeUnmarshaler[f.Type()]
+	if ok {
+		v, err := fn(val)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(v))
+		return nil
+	}
+	if err := json.Unmarshal([]byte(val), f.Addr().Interface()); err != nil {
+		if f.Kind() == reflect.Slice {
+			if f.Type().Elem().Kind() == reflect.String {
+				ss := strings.Split(val, ",")
+				for i := range ss {
+					ss[i] = strings.TrimSpace(ss[i])
 				}
+				f.Set(reflect.ValueOf(ss))
 			} else {
-				parseError.append(key, val, err)
+				if err2 := json.Unmarshal([]byte("["+val+"]"), f.Addr().Interface()); err2 != nil {
+					return err // return first error
+				}
 			}
+		} else {
+			return err
 		}
 	}
+	return nil
+}
 
-	if len(parseError.Items) > 0 {
-		return &parseError
+// nestedPrefix report whether the field is struct that should be expanded, and the prefix for its fields.
+//
+// struct that have its own way to be unmarshaled (e.g. [Unmarshaler], [time.Time], or [json.Unmarshaler]) is not expanded.
+// embedded struct without name in the "env" tag is expanded without additional prefix.
+func nestedPrefix(f reflect.StructField, c envConfig) (string, bool) {
+	if f.Type.Kind() != reflect.Struct {
+		return "", false
+	}
+	if _, ok := nativeUnmarshaler[f.Type]; ok {
+		return "", false
+	}
+	ptr := reflect.PointerTo(f.Type)
+	if ptr.Implements(unmarshalerType) || ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType) {
+		return "", false
+	}
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
 	}
+	if f.Anonymous && !hasEnvName(f) {
+		return "", true
+	}
+	return c.name, true
+}
 
-	return nil
+func hasEnvName(f reflect.StructField) bool {
+	config, _ := f.Tag.Lookup("env")
+	name, _, _ := strings.Cut(config, ",")
+	return name != ""
 }
 
 type envConfig struct {
@@ -98,10 +189,20 @@ type envConfig struct {
 	noUnset  bool
 	skip     bool
 	required bool
+
+	defaultValue string
+	hasDefault   bool
+	oneOf        string
+	min          string // as written in the tag, parsed into minVal
+	max          string // as written in the tag, parsed into maxVal
+	minVal       float64
+	maxVal       float64
+	pattern      string
+	patternRe    *regexp.Regexp
 }
 
 func lookupEnvConfig(f reflect.StructField) (c envConfig) {
-	if !f.IsExported() {
+	if !f.IsExported() && !f.Anonymous {
 		return c
 	}
 
@@ -116,17 +217,47 @@ func lookupEnvConfig(f reflect.StructField) (c envConfig) {
 		c.name = f.Name
 	}
 
+	// option with value can contain comma, so unknown option after it is part of its value
+	var last *string
 	for _, opt := range configParts[1:] {
+		name, value, hasValue := strings.Cut(opt, "=")
 		if opt == "nounset" {
-			c.noUnset = true
+			c.noUnset, last = true, nil
 		} else if opt == "skip" {
-			c.skip = true
+			c.skip, last = true, nil
 		} else if opt == "required" {
-			c.required = true
+			c.required, last = true, nil
+		} else if hasValue && name == "default" {
+			c.defaultValue, c.hasDefault, last = value, true, &c.defaultValue
+		} else if hasValue && name == "oneof" {
+			c.oneOf, last = value, &c.oneOf
+		} else if hasValue && name == "min" {
+			c.min, last = value, &c.min
+		} else if hasValue && name == "max" {
+			c.max, last = value, &c.max
+		} else if hasValue && name == "pattern" {
+			c.pattern, last = value, &c.pattern
+		} else if last != nil {
+			*last += "," + opt
 		} else if opt != "" {
 			panic("envparser: unknown tag option: " + opt)
 		}
 	}
+
+	// validate the options eagerly, so invalid tag panic even when the env is not set
+	if c.pattern != "" {
+		re, err := regexp.Compile(c.pattern)
+		if err != nil {
+			panic("envparser: invalid pattern tag option: " + err.Error())
+		}
+		c.patternRe = re
+	}
+	if c.min != "" {
+		c.minVal = parseBound(f.Type, c.min)
+	}
+	if c.max != "" {
+		c.maxVal = parseBound(f.Type, c.max)
+	}
 	return c
 }
 
@@ -145,17 +276,30 @@ func valueOfPointerToStruct(target any) reflect.Value {
 // List env names from target.
 //
 // target must be non-nil pointer to struct.
+//
+// nested struct is listed with the env names of its fields only, its own env name (read as JSON) is not listed, see [Unmarshal].
 func ListEnvName(target any) []string {
 	targetVal := valueOfPointerToStruct(target)
+	return listEnvName(targetVal.Type(), "")
+}
 
+func listEnvName(t reflect.Type, prefix string) []string {
 	var ret []string
-	for i, t := 0, targetVal.Type(); i < t.NumField(); i++ {
-		envConfig := lookupEnvConfig(targetVal.Type().Field(i))
+	for i := 0; i < t.NumField(); i++ {
+		envConfig := lookupEnvConfig(t.Field(i))
 		if envConfig.skip {
 			continue
 		}
 
-		ret = append(ret, envConfig.name)
+		if nested, ok := nestedPrefix(t.Field(i), envConfig); ok {
+			ret = append(ret, listEnvName(t.Field(i).Type, prefix+nested)...)
+			continue
+		}
+		if !t.Field(i).IsExported() {
+			continue
+		}
+
+		ret = append(ret, prefix+envConfig.name)
 	}
 
 	return ret
@@ -274,3 +274,102 @@ func TestRequiredButMissing(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+type testBaseConfig struct {
+	Name string `env:"NAME"`
+}
+
+type testHiddenConfig struct {
+	Hidden string `env:"HIDDEN"`
+}
+
+type testNestedConfig struct {
+	testBaseConfig
+	testHiddenConfig `env:"SUB_"`
+	DB               struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT,nounset"`
+	} `env:"DB_"`
+	Cache struct {
+		Addr string `env:"ADDR"`
+	} `env:"CACHE_,required"`
+	Started time.Time `env:"STARTED"`
+}
+
+func TestNested(t *testing.T) {
+	fakeEnv := map[string]string{
+		"APP_NAME":       "svc",
+		"APP_SUB_HIDDEN": "h",
+		"APP_DB_HOST":    "localhost",
+		"APP_DB_PORT":    "5432",
+		"APP_STARTED":    "2021-09-14T12:13:14Z",
+	}
+	for k, v := range fakeEnv {
+		os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range fakeEnv {
+			os.Unsetenv(k)
+		}
+	}()
+
+	var config testNestedConfig
+	err := envparser.UnmarshalWithPrefix(&config, "APP_")
+
+	var parseError *envparser.ParseError
+	if !errors.As(err, &parseError) || len(parseError.Items) != 1 ||
+		parseError.Items[0].Key != "APP_CACHE_ADDR" || parseError.Items[0].Cause != envparser.ErrCauseRequired {
+		t.Fatal(err)
+	}
+
+	if config.Name != "svc" || config.Hidden != "h" || config.DB.Host != "localhost" || config.DB.Port != 5432 || config.Started.IsZero() {
+		t.Fatalf("%+v", config)
+	}
+
+	if _, ok := os.LookupEnv("APP_DB_HOST"); ok {
+		t.Fatal("APP_DB_HOST should be unset")
+	}
+	if _, ok := os.LookupEnv("APP_DB_PORT"); !ok {
+		t.Fatal("APP_DB_PORT should not be unset")
+	}
+}
+
+func TestNestedJSON(t *testing.T) {
+	os.Setenv("DB_", `{"Host": "db", "Port": 1}`)
+	defer os.Unsetenv("DB_")
+
+	var config testNestedConfig
+	envparser.Unmarshal(&config)
+	if config.DB.Host != "db" || config.DB.Port != 1 {
+		t.Fatalf("%+v", config)
+	}
+}
+
+func TestNestedUntagged(t *testing.T) {
+	os.Setenv("DBHost", "db")
+	defer os.Unsetenv("DBHost")
+
+	var config struct {
+		DB struct {
+			Host string
+		}
+	}
+	envparser.Unmarshal(&config)
+	if config.DB.Host != "db" {
+		t.Fatalf("untagged nested struct should use the field name as prefix without separator, got %+v", config)
+	}
+}
+
+func TestListEnvNameNested(t *testing.T) {
+	names := envparser.ListEnvName(&testNestedConfig{})
+	if !reflect.DeepEqual(names, []string{
+		"NAME",
+		"SUB_HIDDEN",
+		"DB_HOST",
+		"DB_PORT",
+		"CACHE_ADDR",
+		"STARTED",
+	}) {
+		t.Fatal(names)
+	}
+}
@@ -1,6 +1,7 @@
 package envparser
 
 import (
+	"encoding"
 	"encoding/base64"
 	"encoding/json"
 	"net/url"
@@ -12,11 +13,13 @@ import (
 type Unmarshaler interface{ UnmarshalEnv(val string) error }
 
 var (
-	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
-	timeType        = reflect.TypeOf((*time.Time)(nil)).Elem()
-	durationType    = reflect.TypeOf((*time.Duration)(nil)).Elem()
-	locationType    = reflect.TypeOf((**time.Location)(nil)).Elem()
-	urlType         = reflect.TypeOf((**url.URL)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf((*time.Time)(nil)).Elem()
+	durationType        = reflect.TypeOf((*time.Duration)(nil)).Elem()
+	locationType        = reflect.TypeOf((**time.Location)(nil)).Elem()
+	urlType             = reflect.TypeOf((**url.URL)(nil)).Elem()
 )
 
 var nativeUnmarshaler = map[reflect.Type]func(val string) (any, error){
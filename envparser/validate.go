@@ -0,0 +1,99 @@
+package envparser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is called after the value is unmarshaled, see [Unmarshal].
+//
+// the returned error is collected into [ParseError], if it is [*ParseError], its items are collected instead.
+type Validator interface{ Validate() error }
+
+// check the raw env value against "oneof" and "pattern" option
+func checkValue(val string, c envConfig) error {
+	if c.oneOf != "" {
+		options := strings.Split(c.oneOf, "|")
+		found := false
+		for _, o := range options {
+			if val == o {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: must be one of %s", ErrCauseInvalid, strings.Join(options, ", "))
+		}
+	}
+
+	if c.patternRe != nil && !c.patternRe.MatchString(val) {
+		return fmt.Errorf("%w: must match pattern %s", ErrCauseInvalid, c.pattern)
+	}
+
+	return nil
+}
+
+// parse "min" or "max" option for field of type t, panic if it is invalid
+func parseBound(t reflect.Type, bound string) float64 {
+	var b float64
+	var err error
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(bound)
+			b = float64(d)
+			break
+		}
+		b, err = strconv.ParseFloat(bound, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		b, err = strconv.ParseFloat(bound, 64)
+	default:
+		panic("envparser: min/max tag option is only for numeric field, got " + t.String())
+	}
+	if err != nil {
+		panic("envparser: invalid min/max tag option: " + bound)
+	}
+	return b
+}
+
+// check the unmarshaled field against "min" and "max" option
+func checkRange(f reflect.Value, c envConfig) error {
+	if c.min == "" && c.max == "" {
+		return nil
+	}
+
+	var v float64
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v = float64(f.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v = float64(f.Uint())
+	case reflect.Float32, reflect.Float64:
+		v = f.Float()
+	}
+
+	if c.min != "" && v < c.minVal {
+		return fmt.Errorf("%w: must be at least %s", ErrCauseInvalid, c.min)
+	}
+	if c.max != "" && v > c.maxVal {
+		return fmt.Errorf("%w: must be at most %s", ErrCauseInvalid, c.max)
+	}
+	return nil
+}
+
+// call Validate of targetVal if it implement [Validator]
+func validateStruct(targetVal reflect.Value, prefix string, parseError *ParseError) {
+	if !targetVal.CanAddr() || !targetVal.Addr().CanInterface() {
+		return
+	}
+	if v, ok := targetVal.Addr().Interface().(Validator); ok {
+		if err := v.Validate(); err != nil {
+			parseError.appendValidation(prefix, "", err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package envparser_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"go.winto.dev/envparser"
+)
+
+type testPort int
+
+func (p testPort) Validate() error {
+	if p == 22 {
+		return errors.New("reserved port")
+	}
+	return nil
+}
+
+type testValidatedConfig struct {
+	Mode    string        `env:"MODE,oneof=dev|prod,default=dev"`
+	Level   string        `env:"LEVEL,oneof=debug|info"`
+	Workers int           `env:"WORKERS,min=1,max=8"`
+	Ratio   float64       `env:"RATIO,min=0,max=1"`
+	Timeout time.Duration `env:"TIMEOUT,min=1s,max=1m,default=10s"`
+	Name    string        `env:"NAME,pattern=^[a-z]{1,3}$"`
+	List    []string      `env:"LIST,default=a,b,c"`
+	Port    testPort      `env:"PORT"`
+	DB      struct {
+		Host string `env:"HOST,default=localhost"`
+	} `env:"DB_"`
+}
+
+func (c *testValidatedConfig) Validate() error {
+	if c.Mode == "prod" && c.DB.Host == "localhost" {
+		return errors.New("prod must not use localhost")
+	}
+	return nil
+}
+
+func TestDefault(t *testing.T) {
+	var config testValidatedConfig
+	if err := envparser.Unmarshal(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Mode != "dev" || config.Timeout != 10*time.Second || config.DB.Host != "localhost" ||
+		len(config.List) != 3 || config.List[2] != "c" {
+		t.Fatalf("%+v", config)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	fakeEnv := map[string]string{
+		"MODE":    "prod",
+		"LEVEL":   "trace",
+		"WORKERS": "9",
+		"RATIO":   "0.5",
+		"TIMEOUT": "100ms",
+		"NAME":    "abcd",
+		"PORT":    "22",
+	}
+	for k, v := range fakeEnv {
+		os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range fakeEnv {
+			os.Unsetenv(k)
+		}
+	}()
+
+	var config testValidatedConfig
+	err := envparser.Unmarshal(&config)
+
+	var parseError *envparser.ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatal(err)
+	}
+
+	keys := make(map[string]error)
+	for _, item := range parseError.Items {
+		keys[item.Key] = item.Cause
+	}
+	for _, key := range []string{"LEVEL", "WORKERS", "TIMEOUT", "NAME"} {
+		if !errors.Is(keys[key], envparser.ErrCauseInvalid) {
+			t.Fatalf("%s: %v", key, keys[key])
+		}
+	}
+	if keys["PORT"] == nil || keys["PORT"].Error() != "reserved port" {
+		t.Fatal(keys["PORT"])
+	}
+	if keys[""] == nil {
+		t.Fatal("Validate of the target should be called")
+	}
+	if len(parseError.Items) != 6 {
+		t.Fatal(parseError)
+	}
+}
+
+type testValidatorParseError struct {
+	A int
+}
+
+func (c testValidatorParseError) Validate() error {
+	var p envparser.ParseError
+	p.Items = append(p.Items, struct {
+		Key   string
+		Value string
+		Cause error
+	}{"A", "", errors.New("bad A")})
+	return &p
+}
+
+func TestValidateParseError(t *testing.T) {
+	var config testValidatorParseError
+	err := envparser.Unmarshal(&config)
+
+	var parseError *envparser.ParseError
+	if !errors.As(err, &parseError) || len(parseError.Items) != 1 || parseError.Items[0].Key != "A" {
+		t.Fatal(err)
+	}
+}
+
+func TestUnknownOption(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.FailNow()
+		}
+	}()
+
+	var config struct {
+		A int `env:"A,unknown"`
+	}
+	envparser.Unmarshal(&config)
+}
+
+func TestInvalidOptionUnset(t *testing.T) {
+	// invalid option must panic even when the env is not set
+	for name, target := range map[string]any{
+		"pattern": &struct {
+			A string `env:"A,pattern=("`
+		}{},
+		"min": &struct {
+			A int `env:"A,min=x"`
+		}{},
+		"max": &struct {
+			A time.Duration `env:"A,max=10"`
+		}{},
+		"nonnumeric": &struct {
+			A string `env:"A,min=1"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("invalid %s option should panic", name)
+				}
+			}()
+			envparser.Unmarshal(target)
+		}()
+	}
+}
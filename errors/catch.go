@@ -1,5 +1,7 @@
 package errors
 
+import "fmt"
+
 // run f, if f panic or returned, that value will be returned by this function.
 func Catch(f func() error) (err error) {
 	defer func() {
@@ -10,7 +12,7 @@ func Catch(f func() error) (err error) {
 
 		recErr, ok := rec.(error)
 		if !ok {
-			err = &traced[any]{getLocs(1), rec}
+			err = &traced[any]{stack: captureStack(1, true), e: rec}
 			return
 		}
 
@@ -20,7 +22,7 @@ func Catch(f func() error) (err error) {
 		}
 
 		// error from recovered panic must have stack trace
-		err = Errorf("panic: %w", recErr)
+		err = &traced[error]{stack: captureStack(1, true), e: fmt.Errorf("panic: %w", recErr)}
 	}()
 
 	return f()
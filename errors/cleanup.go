@@ -0,0 +1,95 @@
+package errors
+
+import "io"
+
+// Cleanup call f and join the returned error into *errp, intended to be used with defer.
+//
+//	func write(f *os.File) (err error) {
+//		w := bufio.NewWriter(f)
+//		defer errors.Cleanup(&err, w.Flush)
+//		...
+//	}
+func Cleanup(errp *error, f func() error) {
+	if cerr := f(); cerr != nil {
+		*errp = joinNonNil(*errp, traceIfNeeded(cerr, 1, false))
+	}
+}
+
+// CleanupIgnore is similar to [Cleanup] but the error returned by f is dropped if it match any of ignored (see [Is]),
+// useful when f report that there is nothing left to clean up.
+//
+//	func run() (err error) {
+//		tx, err := db.Begin()
+//		if err != nil {
+//			return err
+//		}
+//		// Rollback return sql.ErrTxDone after successful Commit
+//		defer errors.CleanupIgnore(&err, tx.Rollback, sql.ErrTxDone)
+//		...
+//		return tx.Commit()
+//	}
+func CleanupIgnore(errp *error, f func() error, ignored ...error) {
+	cerr := f()
+	if cerr == nil {
+		return
+	}
+	for _, target := range ignored {
+		if Is(cerr, target) {
+			return
+		}
+	}
+	*errp = joinNonNil(*errp, traceIfNeeded(cerr, 1, false))
+}
+
+// CleanupClose is shorthand for [Cleanup] with c.Close.
+//
+//	defer errors.CleanupClose(&err, file)
+func CleanupClose(errp *error, c io.Closer) {
+	if cerr := c.Close(); cerr != nil {
+		*errp = joinNonNil(*errp, traceIfNeeded(cerr, 1, false))
+	}
+}
+
+// Cleanups is stack of cleanup functions, see [WithCleanups].
+type Cleanups struct {
+	fs []func() error
+}
+
+// Defer register f to be called when the function passed to [WithCleanups] returned or panic.
+func (c *Cleanups) Defer(f func() error) {
+	c.fs = append(c.fs, f)
+}
+
+// DeferClose is shorthand for [Cleanups.Defer] with closer.Close.
+func (c *Cleanups) DeferClose(closer io.Closer) {
+	c.fs = append(c.fs, closer.Close)
+}
+
+// WithCleanups run f, and then run every cleanup function registered by f in reverse order,
+// even if f or other cleanup function panic.
+//
+// panic is converted into error like [Catch], the returned error is the error returned by f
+// joined with every error returned by the cleanup functions.
+func WithCleanups(f func(c *Cleanups) error) error {
+	var c Cleanups
+	errs := []error{Catch(func() error { return f(&c) })}
+	for i := len(c.fs) - 1; i >= 0; i-- {
+		errs = append(errs, Catch(func() error { return Trace(c.fs[i]()) }))
+	}
+	return joinNonNil(errs...)
+}
+
+// like Join, but return the error as is if there is only one non-nil error
+func joinNonNil(errs ...error) error {
+	var nonNil error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if nonNil != nil {
+			return Join(errs...)
+		}
+		nonNil = err
+	}
+	return nonNil
+}
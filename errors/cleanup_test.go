@@ -0,0 +1,135 @@
+package errors_test
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+type testCloser struct {
+	closed bool
+	err    error
+}
+
+func (c *testCloser) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func TestCleanup(t *testing.T) {
+	errClose := fmt.Errorf("close err")
+	errBody := errors.New("body err")
+
+	run := func(bodyErr error, c *testCloser) (err error) {
+		defer errors.CleanupClose(&err, c)
+		return bodyErr
+	}
+
+	c := &testCloser{}
+	if err := run(nil, c); err != nil || !c.closed {
+		t.Fatalf("errors.CleanupClose should close without error")
+	}
+
+	if err := run(nil, &testCloser{err: errClose}); !errors.Is(err, errClose) || len(errors.StackTrace(err)) == 0 {
+		t.Fatalf("errors.CleanupClose should return traced close error")
+	}
+
+	if err := run(errBody, &testCloser{}); err != errBody {
+		t.Fatalf("errors.CleanupClose should keep the error as is")
+	}
+
+	err := run(errBody, &testCloser{err: errClose})
+	if !errors.Is(err, errBody) || !errors.Is(err, errClose) {
+		t.Fatalf("errors.CleanupClose should join the errors")
+	}
+
+	err = func() (err error) {
+		defer errors.Cleanup(&err, func() error { return errClose })
+		return nil
+	}()
+	if !errors.Is(err, errClose) {
+		t.Fatalf("errors.Cleanup should return the cleanup error")
+	}
+}
+
+type testTx struct{ committed, rolledBack bool }
+
+func (tx *testTx) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *testTx) Rollback() error {
+	if tx.committed {
+		return sql.ErrTxDone
+	}
+	tx.rolledBack = true
+	return nil
+}
+
+func TestCleanupIgnore(t *testing.T) {
+	errBody := errors.New("body err")
+
+	run := func(tx *testTx, bodyErr error) (err error) {
+		defer errors.CleanupIgnore(&err, tx.Rollback, sql.ErrTxDone)
+		if bodyErr != nil {
+			return bodyErr
+		}
+		return tx.Commit()
+	}
+
+	tx := &testTx{}
+	if err := run(tx, nil); err != nil || !tx.committed {
+		t.Fatalf("errors.CleanupIgnore should ignore the error of rollback after commit, got %v", err)
+	}
+
+	tx = &testTx{}
+	if err := run(tx, errBody); err != errBody || !tx.rolledBack {
+		t.Fatalf("errors.CleanupIgnore should rollback and keep the error as is, got %v", err)
+	}
+
+	errClose := fmt.Errorf("close err")
+	err := func() (err error) {
+		defer errors.CleanupIgnore(&err, func() error { return errClose }, sql.ErrTxDone)
+		return nil
+	}()
+	if !errors.Is(err, errClose) || len(errors.StackTrace(err)) == 0 {
+		t.Fatalf("errors.CleanupIgnore should return traced error that is not ignored")
+	}
+}
+
+func TestWithCleanups(t *testing.T) {
+	var order []int
+	errCleanup := fmt.Errorf("cleanup err")
+	c3 := &testCloser{}
+
+	err := errors.WithCleanups(func(c *errors.Cleanups) error {
+		c.Defer(func() error { order = append(order, 1); return nil })
+		c.Defer(func() error { order = append(order, 2); panic("cleanup panic") })
+		c.DeferClose(c3)
+		c.Defer(func() error { order = append(order, 4); return errCleanup })
+		funcAA(func() { panic("body panic") })
+		return nil
+	})
+
+	if fmt.Sprint(order) != "[4 2 1]" || !c3.closed {
+		t.Fatalf("cleanup should be called in reverse order, got %v", order)
+	}
+
+	if !errors.Is(err, errCleanup) || len(errors.UnwrapSlice(err)) != 3 {
+		t.Fatalf("errors.WithCleanups should join all errors, got %v", err)
+	}
+
+	if !haveTrace(errors.StackTrace(err), "funcAA") {
+		t.Fatalf("panic in body should be converted into traced error")
+	}
+
+	if errors.WithCleanups(func(c *errors.Cleanups) error {
+		c.Defer(func() error { return nil })
+		return nil
+	}) != nil {
+		t.Fatalf("errors.WithCleanups should return nil when there is no error")
+	}
+}
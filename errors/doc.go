@@ -5,4 +5,6 @@
 // This package provide StackTrace function to get the stack trace.
 //
 // Stack trace can be attached to any error by passing it to Trace function.
+//
+// Key/value context can be attached to any error by passing it to WithFields function.
 package errors
@@ -38,7 +38,7 @@ func Unwrap(err error) error {
 //
 // [stdlib errors.New]: https://pkg.go.dev/errors/#New
 func New(text string) error {
-	return &traced[error]{getLocs(1), stderrors.New(text)}
+	return &traced[error]{stack: captureStack(1, false), e: stderrors.New(text)}
 }
 
 // see [stdlib fmt.Errorf].
@@ -49,7 +49,7 @@ func Errorf(format string, a ...any) error {
 	if _, ok := err.(unwrapslice); ok {
 		return err
 	}
-	return &traced[error]{getLocs(1), err}
+	return &traced[error]{stack: captureStack(1, false), e: err}
 }
 
 // see [stdlib errors.Join].
@@ -64,7 +64,7 @@ func Join(errs ...error) error {
 // usage of this function is discouraged.
 func Check(err error) {
 	if err != nil && err != syscall.Errno(0) {
-		panic(traceIfNeeded(err, 1))
+		panic(traceIfNeeded(err, 1, true))
 	}
 }
 
@@ -76,7 +76,7 @@ func Expect(fact bool, message string) {
 		if message == "" {
 			message = "expectation failed"
 		}
-		panic(&traced[error]{getLocs(1), stderrors.New(message)})
+		panic(&traced[error]{stack: captureStack(1, true), e: stderrors.New(message)})
 	}
 }
 
@@ -0,0 +1,199 @@
+package errorstest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go.winto.dev/errors"
+)
+
+type fataler = interface{ Fatal(...any) }
+
+func helper(t fataler) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+}
+
+func fatalf(t fataler, format string, a ...any) {
+	t.Fatal(fmt.Sprintf(format, a...))
+	panic("unreachable")
+}
+
+// Is assert that [errors.Is] (err, target) is true.
+//
+// [errors.Is]: https://pkg.go.dev/go.winto.dev/errors#Is
+func Is(t fataler, err, target error) {
+	helper(t)
+	if !errors.Is(err, target) {
+		fatalf(t, "error is not %v:\n%s", target, formatOrNil(err))
+	}
+}
+
+// As assert that err can be converted to T by [errors.AsType], and return it.
+//
+// [errors.AsType]: https://pkg.go.dev/go.winto.dev/errors#AsType
+func As[T error](t fataler, err error) T {
+	helper(t)
+	target, ok := errors.AsType[T](err)
+	if !ok {
+		fatalf(t, "error is not %s:\n%s", reflect.TypeFor[T](), formatOrNil(err))
+	}
+	return target
+}
+
+// Message assert that err.Error() is equal to want.
+func Message(t fataler, err error, want string) {
+	helper(t)
+	if err == nil {
+		fatalf(t, "error is nil, expecting message %q", want)
+	}
+	if got := err.Error(); got != want {
+		fatalf(t, "error message is %q, expecting %q", got, want)
+	}
+}
+
+// MessageMatch assert that err.Error() match the regular expression pattern.
+func MessageMatch(t fataler, err error, pattern string) {
+	helper(t)
+	if err == nil {
+		fatalf(t, "error is nil, expecting message matching %q", pattern)
+	}
+	if got := err.Error(); !regexp.MustCompile(pattern).MatchString(got) {
+		fatalf(t, "error message is %q, expecting match with %q", got, pattern)
+	}
+}
+
+// InPkg assert that stack trace of err contains location in one of pkgs, see [errors.Location.InPkg].
+//
+// every branch of the error tree is checked, see [errors.StackTraces].
+//
+// [errors.Location.InPkg]: https://pkg.go.dev/go.winto.dev/errors#Location.InPkg
+// [errors.StackTraces]: https://pkg.go.dev/go.winto.dev/errors#StackTraces
+func InPkg(t fataler, err error, pkgs ...string) {
+	helper(t)
+	if !haveLocation(err, func(l errors.Location) bool { return l.InPkg(pkgs...) }) {
+		fatalf(t, "stack trace doesn't contain location in %q:\n%s", pkgs, formatOrNil(err))
+	}
+}
+
+// InFunc assert that stack trace of err contains location in one of funcs.
+//
+// the function name can be path-qualified (e.g. "example.com/pkg.Func") or just suffix of it (e.g. "Func" or "pkg.Func").
+//
+// every branch of the error tree is checked, see [errors.StackTraces].
+//
+// [errors.StackTraces]: https://pkg.go.dev/go.winto.dev/errors#StackTraces
+func InFunc(t fataler, err error, funcs ...string) {
+	helper(t)
+	if !haveLocation(err, func(l errors.Location) bool {
+		for _, f := range funcs {
+			if l.Func() == f || strings.HasSuffix(l.Func(), "."+f) {
+				return true
+			}
+		}
+		return false
+	}) {
+		fatalf(t, "stack trace doesn't contain location in %q:\n%s", funcs, formatOrNil(err))
+	}
+}
+
+func haveLocation(err error, match func(errors.Location) bool) bool {
+	for _, locs := range errors.StackTraces(err) {
+		for _, l := range locs {
+			if match(l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Panics assert that f panic with want, and return the error as returned by [errors.Catch0].
+//
+// if want is error, it is compared with [errors.Is], otherwise it is compared with [reflect.DeepEqual].
+//
+// [errors.Catch0]: https://pkg.go.dev/go.winto.dev/errors#Catch0
+// [errors.Is]: https://pkg.go.dev/go.winto.dev/errors#Is
+// [reflect.DeepEqual]: https://pkg.go.dev/reflect#DeepEqual
+func Panics(t fataler, want any, f func()) error {
+	helper(t)
+
+	var rec any
+	panicked := true
+	err := errors.Catch0(func() {
+		defer func() {
+			if panicked {
+				rec = recover()
+				panic(rec)
+			}
+		}()
+		f()
+		panicked = false
+	})
+
+	if !panicked {
+		fatalf(t, "expecting panic with %v, but not panic", want)
+	}
+
+	if wantErr, ok := want.(error); ok {
+		if recErr, ok := rec.(error); !ok || !errors.Is(recErr, wantErr) {
+			fatalf(t, "expecting panic with %v, got %v:\n%s", want, rec, formatOrNil(err))
+		}
+	} else if !reflect.DeepEqual(rec, want) {
+		fatalf(t, "expecting panic with %v, got %v:\n%s", want, rec, formatOrNil(err))
+	}
+
+	return err
+}
+
+var locationRe = regexp.MustCompile(`(?:[^\s]*/)?([^/\s]+\.go):\d+`)
+
+// NormalizeFormat replace every "path/to/file.go:123" in s with "file.go:N",
+// so the output of [errors.Format] can be compared across machines and edits.
+//
+// [errors.Format]: https://pkg.go.dev/go.winto.dev/errors#Format
+func NormalizeFormat(s string) string {
+	return locationRe.ReplaceAllString(s, "${1}:N")
+}
+
+// FormatGolden assert that normalized [errors.FormatTree] of err (see [NormalizeFormat]) is equal to content of golden file.
+//
+// if environment variable ERRORSTEST_UPDATE_GOLDEN is set to non-empty, the golden file will be updated instead.
+//
+// [errors.FormatTree]: https://pkg.go.dev/go.winto.dev/errors#FormatTree
+func FormatGolden(t fataler, err error, golden string) {
+	helper(t)
+
+	got := NormalizeFormat(formatOrNil(err))
+
+	if os.Getenv("ERRORSTEST_UPDATE_GOLDEN") != "" {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			fatalf(t, "cannot create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			fatalf(t, "cannot update golden file: %v", err)
+		}
+		return
+	}
+
+	want, readErr := os.ReadFile(golden)
+	if readErr != nil {
+		fatalf(t, "cannot read golden file (set ERRORSTEST_UPDATE_GOLDEN=1 to create it): %v", readErr)
+	}
+
+	if got != string(want) {
+		fatalf(t, "formatted error is different with golden file %s:\n--- got:\n%s--- want:\n%s", golden, got, want)
+	}
+}
+
+func formatOrNil(err error) string {
+	if err == nil {
+		return "<nil>\n"
+	}
+	return errors.FormatTree(err)
+}
@@ -0,0 +1,106 @@
+package errorstest_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+	"go.winto.dev/errors/errorstest"
+)
+
+type fakeT struct{ msg string }
+
+type fakeFatal struct{}
+
+func (t *fakeT) Fatal(a ...any) {
+	t.msg = fmt.Sprint(a...)
+	panic(fakeFatal{})
+}
+
+// run f with fake t, return the message passed to Fatal, or empty string if Fatal is not called
+func fatalMsg(f func(t *fakeT)) (msg string) {
+	t := &fakeT{}
+	defer func() {
+		if rec := recover(); rec != nil {
+			if _, ok := rec.(fakeFatal); !ok {
+				panic(rec)
+			}
+			msg = t.msg
+		}
+	}()
+	f(t)
+	return ""
+}
+
+type myErr struct{}
+
+func (myErr) Error() string { return "myerr" }
+
+func funcAA(f func()) { f() }
+
+func TestAssertions(t *testing.T) {
+	var err error
+	funcAA(func() { err = errors.Errorf("wrapper: %w", myErr{}) })
+
+	for _, tc := range []struct {
+		name   string
+		failed bool
+		f      func(t *fakeT)
+	}{
+		{"Is", false, func(t *fakeT) { errorstest.Is(t, err, myErr{}) }},
+		{"IsFailed", true, func(t *fakeT) { errorstest.Is(t, err, errors.ErrUnsupported) }},
+		{"As", false, func(t *fakeT) { errorstest.As[myErr](t, err) }},
+		{"AsFailed", true, func(t *fakeT) { errorstest.As[*os.PathError](t, err) }},
+		{"Message", false, func(t *fakeT) { errorstest.Message(t, err, "wrapper: myerr") }},
+		{"MessageFailed", true, func(t *fakeT) { errorstest.Message(t, err, "myerr") }},
+		{"MessageNil", true, func(t *fakeT) { errorstest.Message(t, nil, "myerr") }},
+		{"MessageMatch", false, func(t *fakeT) { errorstest.MessageMatch(t, err, "^wrapper: ") }},
+		{"MessageMatchFailed", true, func(t *fakeT) { errorstest.MessageMatch(t, err, "^myerr") }},
+		{"InPkg", false, func(t *fakeT) { errorstest.InPkg(t, err, "go.winto.dev/errors/errorstest_test") }},
+		{"InPkgFailed", true, func(t *fakeT) { errorstest.InPkg(t, err, "go.winto.dev/errors/errorstest") }},
+		{"InFunc", false, func(t *fakeT) { errorstest.InFunc(t, err, "funcAA") }},
+		{"InFuncFailed", true, func(t *fakeT) { errorstest.InFunc(t, err, "AA") }},
+		{"Panics", false, func(t *fakeT) { errorstest.Panics(t, "testpanic", func() { panic("testpanic") }) }},
+		{"PanicsError", false, func(t *fakeT) { errorstest.Panics(t, myErr{}, func() { panic(err) }) }},
+		{"PanicsFailed", true, func(t *fakeT) { errorstest.Panics(t, "testpanic", func() { panic("other") }) }},
+		{"PanicsNoPanic", true, func(t *fakeT) { errorstest.Panics(t, "testpanic", func() {}) }},
+	} {
+		msg := fatalMsg(tc.f)
+		if tc.failed && msg == "" {
+			t.Errorf("%s: should fail", tc.name)
+		} else if !tc.failed && msg != "" {
+			t.Errorf("%s: should not fail, got: %s", tc.name, msg)
+		}
+	}
+}
+
+func TestPanicsReturnTracedError(t *testing.T) {
+	err := errorstest.Panics(t, "testpanic", func() { funcAA(func() { panic("testpanic") }) })
+	errorstest.InFunc(t, err, "funcAA")
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	got := errorstest.NormalizeFormat("Error => a\n  - /a/b/c.go:12 (x.y)\n  |-- d.go:3\n")
+	if got != "Error => a\n  - c.go:N (x.y)\n  |-- d.go:N\n" {
+		t.Fatalf("invalid NormalizeFormat: %q", got)
+	}
+}
+
+func TestFormatGolden(t *testing.T) {
+	var err error
+	funcAA(func() { err = errors.Errorf("wrapper: %w", errors.New("testerr")) })
+	errors.SetFormatFilterPkgs("go.winto.dev/errors/errorstest_test")
+	defer errors.SetFormatFilterPkgs()
+
+	errorstest.FormatGolden(t, err, "testdata/format.golden")
+
+	tmp := filepath.Join(t.TempDir(), "format.golden")
+	os.WriteFile(tmp, []byte("other"), 0o644)
+	msg := fatalMsg(func(t *fakeT) { errorstest.FormatGolden(t, err, tmp) })
+	if !strings.Contains(msg, "different with golden file") {
+		t.Fatalf("FormatGolden should fail on different content")
+	}
+}
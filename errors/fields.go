@@ -0,0 +1,72 @@
+package errors
+
+// Single key/value context attached to the error.
+type Field struct {
+	Key   string
+	Value any
+}
+
+type fielder interface {
+	Fields() []Field
+}
+
+func (e *traced[E]) Fields() []Field { return e.fields }
+
+// WithField is shorthand for [WithFields] with single field.
+func WithField(err error, key string, value any) error {
+	if err == nil {
+		return nil
+	}
+
+	return withFields(err, []Field{{key, value}}, 1)
+}
+
+// WithFields will return new error that have fields attached to it.
+//
+// err is wrapped (never copied), so [Is] still match err. if err doesn't have stack trace,
+// the new error will have it, see [Trace].
+//
+// the fields will survive wrapping, use [Fields] to collect them.
+func WithFields(err error, fields ...Field) error {
+	if err == nil {
+		return nil
+	}
+
+	return withFields(err, fields, 1)
+}
+
+func withFields(err error, fields []Field, skip int) error {
+	if len(fields) == 0 {
+		return err
+	}
+
+	return annotate(err, skip+1, true, func(a *annotations) {
+		a.fields = appendFields(a.fields, fields)
+	})
+}
+
+func appendFields(a, b []Field) []Field {
+	ret := make([]Field, 0, len(a)+len(b))
+	ret = append(ret, a...)
+	return append(ret, b...)
+}
+
+// Fields collect all fields attached to err and all errors wrapped by it.
+//
+// the outermost fields come first, errors in [UnwrapSlice] are visited in order.
+func Fields(err error) []Field {
+	var ret []Field
+	for err != nil {
+		if f, ok := err.(fielder); ok {
+			ret = append(ret, f.Fields()...)
+		}
+		if errs := UnwrapSlice(err); errs != nil {
+			for _, e := range errs {
+				ret = append(ret, Fields(e)...)
+			}
+			break
+		}
+		err = Unwrap(err)
+	}
+	return ret
+}
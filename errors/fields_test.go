@@ -0,0 +1,115 @@
+package errors_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+func TestWithFieldsNil(t *testing.T) {
+	if errors.WithField(nil, "a", 1) != nil {
+		t.Errorf("errors.WithField(nil) should be nil")
+	}
+}
+
+func TestWithFieldsSameLayer(t *testing.T) {
+	err1 := errors.New("testerr")
+	err2 := errors.WithField(err1, "a", 1)
+
+	if errors.Unwrap(err2) != err1 {
+		t.Errorf("errors.WithField should wrap the original error")
+	}
+
+	if err2.Error() != "testerr" {
+		t.Errorf("errors.WithField should not change error message")
+	}
+
+	if !reflect.DeepEqual(errors.StackTrace(err1), errors.StackTrace(err2)) {
+		t.Errorf("errors.WithField should keep stack trace")
+	}
+}
+
+var errFieldsSentinel = errors.New("sentinel")
+
+func TestWithFieldsSentinel(t *testing.T) {
+	err := errors.WithField(errFieldsSentinel, "a", 1)
+	if !errors.Is(err, errFieldsSentinel) {
+		t.Errorf("errors.WithField should keep errors.Is of traced sentinel")
+	}
+
+	err = errors.WithField(fmt.Errorf("wrapper: %w", err), "b", 2)
+	if !errors.Is(err, errFieldsSentinel) {
+		t.Errorf("errors.WithField should keep errors.Is of wrapped traced sentinel")
+	}
+
+	if strings.Count(errors.Format(errors.WithField(errFieldsSentinel, "a", 1)), "Error => ") != 1 {
+		t.Errorf("errors.Format should render the fields in the same layer as the traced error")
+	}
+}
+
+func TestFields(t *testing.T) {
+	var err error
+	funcAA(func() {
+		err = errors.WithFields(errors.New("err1"),
+			errors.Field{Key: "a", Value: 1},
+			errors.Field{Key: "b", Value: "x"},
+		)
+	})
+	err = errors.WithField(errors.Errorf("err2: %w", err), "c", true)
+	err = errors.WithField(fmt.Errorf("err3: %w", err), "d", 2)
+
+	if !haveTrace(errors.StackTrace(err), "TestFields") {
+		t.Errorf("errors.StackTrace should use the outermost traced error")
+	}
+
+	if !reflect.DeepEqual(errors.Fields(err), []errors.Field{
+		{Key: "d", Value: 2},
+		{Key: "c", Value: true},
+		{Key: "a", Value: 1},
+		{Key: "b", Value: "x"},
+	}) {
+		t.Errorf("invalid errors.Fields")
+	}
+
+	f := errors.Format(err)
+	if !strings.Contains(f, "- a: 1\n") ||
+		!strings.Contains(f, "- c: true\n") ||
+		!strings.Contains(f, "- d: 2\n") ||
+		strings.Index(f, "- c: true") > strings.Index(f, "Caused by Error => err1") {
+		t.Errorf("invalid errors.Format:\n%s", f)
+	}
+}
+
+func TestFieldsNonTraced(t *testing.T) {
+	var err error
+	funcAA(func() {
+		err = errors.WithField(fmt.Errorf("testerr"), "a", 1)
+	})
+
+	if !haveTrace(errors.StackTrace(err), "funcAA") {
+		t.Errorf("errors.WithField should trace non-traced error")
+	}
+}
+
+func TestFieldsUnwrapSlice(t *testing.T) {
+	var errAa error
+	funcAA(func() { errAa = errors.WithField(errors.New("a"), "a", 1) })
+	errBb := errors.WithField(errors.New("b"), "b", 2)
+
+	err := errors.WithField(errors.Join(errAa, errBb), "c", 3)
+
+	if !haveTrace(errors.StackTrace(err), "funcAA") {
+		t.Errorf("errors.StackTrace should use the first error in the slice")
+	}
+
+	if !reflect.DeepEqual(errors.Fields(err), []errors.Field{
+		{Key: "c", Value: 3},
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 2},
+	}) {
+		t.Errorf("invalid errors.Fields")
+	}
+}
@@ -0,0 +1,160 @@
+package errors
+
+import (
+	"context"
+	"io/fs"
+)
+
+// Kind is classification of the error, see [WithKind] and [KindOf].
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindInvalidArgument
+	KindUnauthenticated
+	KindPermissionDenied
+	KindNotFound
+	KindAlreadyExists
+	KindConflict
+	KindResourceExhausted
+	KindCanceled
+	KindDeadlineExceeded
+	KindUnimplemented
+	KindUnavailable
+	KindInternal
+)
+
+var kindInfos = [...]struct {
+	name     string
+	http     int
+	exitCode int
+}{
+	KindUnknown:           {"Unknown", 500, 1},
+	KindInvalidArgument:   {"InvalidArgument", 400, 64},  // EX_USAGE
+	KindUnauthenticated:   {"Unauthenticated", 401, 77},  // EX_NOPERM
+	KindPermissionDenied:  {"PermissionDenied", 403, 77}, // EX_NOPERM
+	KindNotFound:          {"NotFound", 404, 66},         // EX_NOINPUT
+	KindAlreadyExists:     {"AlreadyExists", 409, 73},    // EX_CANTCREAT
+	KindConflict:          {"Conflict", 409, 75},         // EX_TEMPFAIL
+	KindResourceExhausted: {"ResourceExhausted", 429, 75},
+	KindCanceled:          {"Canceled", 499, 130}, // same as killed by SIGINT
+	KindDeadlineExceeded:  {"DeadlineExceeded", 504, 75},
+	KindUnimplemented:     {"Unimplemented", 501, 69}, // EX_UNAVAILABLE
+	KindUnavailable:       {"Unavailable", 503, 69},   // EX_UNAVAILABLE
+	KindInternal:          {"Internal", 500, 70},      // EX_SOFTWARE
+}
+
+func (k Kind) info() (name string, http int, exitCode int) {
+	if k < 0 || int(k) >= len(kindInfos) {
+		k = KindUnknown
+	}
+	i := kindInfos[k]
+	return i.name, i.http, i.exitCode
+}
+
+// String representation of Kind.
+func (k Kind) String() string {
+	name, _, _ := k.info()
+	return name
+}
+
+// HTTPStatus return the http status code for the Kind.
+func (k Kind) HTTPStatus() int {
+	_, http, _ := k.info()
+	return http
+}
+
+// ExitCode return the process exit code for the Kind, mostly follow sysexits.h convention.
+func (k Kind) ExitCode() int {
+	_, _, exitCode := k.info()
+	return exitCode
+}
+
+type kinder interface {
+	Kind() Kind
+}
+
+func (e *traced[E]) Kind() Kind { return e.kind }
+
+// HTTPStatus return the http status code for the Kind attached to the error, or 0 if there is none.
+//
+// this is used by go.winto.dev/httphandler/defresponse without depending on this package.
+func (e *traced[E]) HTTPStatus() int {
+	if e.kind == KindUnknown {
+		return 0
+	}
+	return e.kind.HTTPStatus()
+}
+
+// WithKind will return new error that have kind attached to it.
+//
+// err is wrapped (never copied), so [Is] still match err. if err doesn't have stack trace,
+// the new error will have it, see [Trace].
+func WithKind(err error, kind Kind) error {
+	if err == nil {
+		return nil
+	}
+
+	return annotate(err, 1, true, func(a *annotations) { a.kind = kind })
+}
+
+// KindOf return the outermost Kind attached to err or any error wrapped by it, see [AttachedKind].
+//
+// if there is none, following errors are classified as well:
+//
+//	context.Canceled          KindCanceled
+//	context.DeadlineExceeded  KindDeadlineExceeded
+//	fs.ErrNotExist            KindNotFound
+//	fs.ErrExist               KindAlreadyExists
+//	fs.ErrPermission          KindPermissionDenied
+//	ErrUnsupported            KindUnimplemented
+//
+// otherwise KindUnknown is returned.
+func KindOf(err error) Kind {
+	if kind := AttachedKind(err); kind != KindUnknown {
+		return kind
+	}
+
+	switch {
+	case err == nil:
+		return KindUnknown
+	case Is(err, context.Canceled):
+		return KindCanceled
+	case Is(err, context.DeadlineExceeded):
+		return KindDeadlineExceeded
+	case Is(err, fs.ErrNotExist):
+		return KindNotFound
+	case Is(err, fs.ErrExist):
+		return KindAlreadyExists
+	case Is(err, fs.ErrPermission):
+		return KindPermissionDenied
+	case Is(err, ErrUnsupported):
+		return KindUnimplemented
+	}
+
+	return KindUnknown
+}
+
+// AttachedKind return the outermost Kind attached to err or any error wrapped by it with [WithKind],
+// or KindUnknown if there is none.
+//
+// unlike [KindOf], errors from standard library are not classified.
+func AttachedKind(err error) Kind {
+	for err != nil {
+		if k, ok := err.(kinder); ok {
+			if kind := k.Kind(); kind != KindUnknown {
+				return kind
+			}
+		}
+		if errs := UnwrapSlice(err); errs != nil {
+			for _, e := range errs {
+				if kind := AttachedKind(e); kind != KindUnknown {
+					return kind
+				}
+			}
+			return KindUnknown
+		}
+		err = Unwrap(err)
+	}
+	return KindUnknown
+}
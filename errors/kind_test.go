@@ -0,0 +1,114 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+func TestKind(t *testing.T) {
+	var err error
+	funcAA(func() { err = errors.WithKind(errors.New("testerr"), errors.KindNotFound) })
+
+	if !haveTrace(errors.StackTrace(err), "funcAA") {
+		t.Errorf("errors.WithKind should keep stack trace")
+	}
+
+	err = errors.Errorf("wrapper: %w", err)
+	if errors.KindOf(err) != errors.KindNotFound {
+		t.Errorf("errors.KindOf should find kind through the chain")
+	}
+
+	err = errors.WithKind(err, errors.KindUnavailable)
+	if errors.KindOf(err) != errors.KindUnavailable {
+		t.Errorf("errors.KindOf should return the outermost kind")
+	}
+
+	f := errors.Format(err)
+	if !strings.Contains(f, "Kind: Unavailable\n") || !strings.Contains(f, "Kind: NotFound\n") {
+		t.Errorf("invalid errors.Format:\n%s", f)
+	}
+}
+
+func TestKindNonTraced(t *testing.T) {
+	var err error
+	funcAA(func() { err = errors.WithKind(fmt.Errorf("testerr"), errors.KindConflict) })
+
+	if !haveTrace(errors.StackTrace(err), "funcAA") {
+		t.Errorf("errors.WithKind should trace non-traced error")
+	}
+
+	err = errors.Join(fmt.Errorf("other"), fmt.Errorf("wrapper: %w", err))
+	if errors.KindOf(err) != errors.KindConflict {
+		t.Errorf("errors.KindOf should find kind in the slice")
+	}
+}
+
+func TestKindFallback(t *testing.T) {
+	_, err := os.Open("InvalidFile.txt")
+	for _, tc := range []struct {
+		err  error
+		kind errors.Kind
+	}{
+		{nil, errors.KindUnknown},
+		{errors.New("testerr"), errors.KindUnknown},
+		{errors.Trace(err), errors.KindNotFound},
+		{errors.Trace(context.Canceled), errors.KindCanceled},
+		{errors.Errorf("wrapper: %w", context.DeadlineExceeded), errors.KindDeadlineExceeded},
+		{errors.WithKind(context.Canceled, errors.KindInternal), errors.KindInternal},
+	} {
+		if kind := errors.KindOf(tc.err); kind != tc.kind {
+			t.Errorf("errors.KindOf(%v) should be %s, got %s", tc.err, tc.kind, kind)
+		}
+	}
+}
+
+var errKindSentinel = errors.New("sentinel")
+
+func TestKindSentinel(t *testing.T) {
+	err := errors.WithKind(errKindSentinel, errors.KindNotFound)
+	if !errors.Is(err, errKindSentinel) {
+		t.Errorf("errors.WithKind should keep errors.Is of traced sentinel")
+	}
+	if errors.KindOf(err) != errors.KindNotFound {
+		t.Errorf("errors.KindOf should find kind attached to sentinel")
+	}
+}
+
+func TestAttachedKind(t *testing.T) {
+	if kind := errors.AttachedKind(errors.Trace(context.Canceled)); kind != errors.KindUnknown {
+		t.Errorf("errors.AttachedKind should not classify standard library error, got %s", kind)
+	}
+
+	err := errors.Errorf("wrapper: %w", errors.WithKind(context.Canceled, errors.KindUnavailable))
+	if kind := errors.AttachedKind(err); kind != errors.KindUnavailable {
+		t.Errorf("errors.AttachedKind should return attached kind, got %s", kind)
+	}
+}
+
+func TestKindMapping(t *testing.T) {
+	if errors.KindNotFound.HTTPStatus() != 404 ||
+		errors.KindUnknown.HTTPStatus() != 500 ||
+		errors.Kind(-1).HTTPStatus() != 500 {
+		t.Errorf("invalid Kind.HTTPStatus")
+	}
+
+	if errors.KindUnknown.ExitCode() != 1 ||
+		errors.KindInvalidArgument.ExitCode() != 64 {
+		t.Errorf("invalid Kind.ExitCode")
+	}
+
+	if errors.KindPermissionDenied.String() != "PermissionDenied" ||
+		errors.Kind(1000).String() != "Unknown" {
+		t.Errorf("invalid Kind.String")
+	}
+
+	var status interface{ HTTPStatus() int }
+	if !errors.As(errors.WithKind(errors.New("testerr"), errors.KindNotFound), &status) || status.HTTPStatus() != 404 {
+		t.Errorf("error with kind should implement HTTPStatus")
+	}
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -46,21 +47,60 @@ func (l *Location) InPkg(pkgs ...string) bool {
 	return false
 }
 
-// skip==0 mean stack trace for where getLocs is called.
-func getLocs(skip int) (locations []Location) {
+// captured stack, the program counters are resolved into locations lazily if needed, see [StackPolicy].
+type stack struct {
+	once sync.Once
+	pcs  []uintptr
+	locs []Location
+}
+
+func (s *stack) locations() []Location {
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		if s.pcs != nil {
+			s.locs = resolveLocs(s.pcs)
+			s.pcs = nil
+		}
+	})
+	return s.locs
+}
+
+// skip==0 mean stack trace for where captureStack is called.
+//
+// return nil if the current [StackPolicy] decide not to capture the stack, unless force is true.
+func captureStack(skip int, force bool) *stack {
+	policy := currentStackPolicy()
+	if !force && (policy.Disabled || (policy.Sample != nil && !policy.Sample())) {
+		return nil
+	}
+
 	var data *pcbuff
 	if tmp, ok := pcbuffPool.Get().(*pcbuff); ok {
 		data = tmp
 	} else {
 		data = new(pcbuff)
 	}
+	defer pcbuffPool.Put(data)
 
 	pc := data[:]
+	if policy.MaxDepth > 0 && policy.MaxDepth < len(pc) {
+		pc = pc[:policy.MaxDepth]
+	}
 	pc = pc[:runtime.Callers(skip+2, pc)]
 	if len(pc) == 0 {
 		return nil
 	}
 
+	if policy.Lazy {
+		return &stack{pcs: slices.Clone(pc)}
+	}
+
+	return &stack{locs: resolveLocs(pc)}
+}
+
+func resolveLocs(pc []uintptr) (locations []Location) {
 	locations = make([]Location, 0, len(pc))
 
 	frames := runtime.CallersFrames(pc)
@@ -68,7 +108,8 @@ func getLocs(skip int) (locations []Location) {
 		frame, more := frames.Next()
 		if frame.Line != 0 && frame.File != "" &&
 			!nameInPkg(frame.Function, "runtime") &&
-			!nameInPkg(frame.Function, "go.winto.dev/errors") {
+			!strings.HasPrefix(frame.Function, "go.winto.dev/errors.") &&
+			!strings.HasPrefix(frame.Function, "go.winto.dev/errors/errorstest.") {
 			locations = append(locations, Location{
 				func_: frame.Function,
 				file:  frame.File,
@@ -80,8 +121,6 @@ func getLocs(skip int) (locations []Location) {
 		}
 	}
 
-	pcbuffPool.Put(data)
-
 	return
 }
 
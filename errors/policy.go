@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// StackPolicy control how stack trace is captured by [New], [Errorf], [Trace], and others.
+//
+// the zero value is the default policy, capture up to 512 frames and resolve them eagerly.
+//
+// stack trace of recovered panic (see [Catch]) and [Check] is always captured regardless of Disabled and Sample.
+type StackPolicy struct {
+	// maximum number of frames to capture, zero mean default (512).
+	MaxDepth int
+
+	// if true, only program counters are captured, they are resolved into [Location] when
+	// the stack trace is requested (e.g. by [StackTrace] or [Format]).
+	Lazy bool
+
+	// if true, stack trace is not captured.
+	Disabled bool
+
+	// if not nil, it is called every time stack trace is about to be captured,
+	// stack trace is not captured if it return false, see [SampleRate].
+	//
+	// it must be safe for concurrent use.
+	Sample func() bool
+}
+
+var stackPolicy atomic.Pointer[StackPolicy]
+
+// SetStackPolicy set the package-level [StackPolicy].
+//
+// it only affect stack trace captured after this call.
+func SetStackPolicy(policy StackPolicy) {
+	stackPolicy.Store(&policy)
+}
+
+func currentStackPolicy() *StackPolicy {
+	if policy := stackPolicy.Load(); policy != nil {
+		return policy
+	}
+	return &defaultStackPolicy
+}
+
+var defaultStackPolicy StackPolicy
+
+// SampleRate return function that can be used as [StackPolicy.Sample],
+// it will return true with probability of rate (0.0 to 1.0).
+func SampleRate(rate float64) func() bool {
+	return func() bool { return rand.Float64() < rate }
+}
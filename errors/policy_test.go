@@ -0,0 +1,84 @@
+package errors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+func TestStackPolicyLazy(t *testing.T) {
+	errors.SetStackPolicy(errors.StackPolicy{Lazy: true})
+	defer errors.SetStackPolicy(errors.StackPolicy{})
+
+	var err error
+	funcAA(func() { err = errors.New("testerr") })
+
+	trace := errors.StackTrace(err)
+	if !haveTrace(trace, "funcAA") {
+		t.Fatalf("lazy stack trace should contains funcAA")
+	}
+	if !reflect.DeepEqual(trace, errors.StackTrace(err)) {
+		t.Fatalf("lazy stack trace should be stable")
+	}
+}
+
+func TestStackPolicyMaxDepth(t *testing.T) {
+	errors.SetStackPolicy(errors.StackPolicy{MaxDepth: 2})
+	defer errors.SetStackPolicy(errors.StackPolicy{})
+
+	var err error
+	funcAA(func() { funcBB(func() { err = errors.New("testerr") }) })
+
+	trace := errors.StackTrace(err)
+	if len(trace) == 0 || len(trace) > 2 || haveTrace(trace, "funcAA") {
+		t.Fatalf("stack trace should be limited to 2 frames")
+	}
+}
+
+func TestStackPolicyDisabled(t *testing.T) {
+	errors.SetStackPolicy(errors.StackPolicy{Disabled: true})
+	defer errors.SetStackPolicy(errors.StackPolicy{})
+
+	err := errors.New("testerr")
+	if errors.StackTrace(err) != nil || err.Error() != "testerr" {
+		t.Fatalf("stack trace should not be captured when disabled")
+	}
+
+	if errors.Trace(err) != err {
+		t.Fatalf("errors.Trace should return same err when disabled")
+	}
+
+	err = errors.Catch(func() error {
+		funcAA(func() { panic(err) })
+		return nil
+	})
+	if !haveTrace(errors.StackTrace(err), "funcAA") {
+		t.Fatalf("stack trace of recovered panic should always be captured")
+	}
+
+	var locs []errors.Location
+	funcAA(func() { locs = errors.Callers(0) })
+	if !haveTrace(locs, "funcAA") {
+		t.Fatalf("errors.Callers should always capture the stack")
+	}
+}
+
+func TestStackPolicySample(t *testing.T) {
+	sampled := false
+	errors.SetStackPolicy(errors.StackPolicy{Sample: func() bool { return sampled }})
+	defer errors.SetStackPolicy(errors.StackPolicy{})
+
+	if errors.StackTrace(errors.New("testerr")) != nil {
+		t.Fatalf("stack trace should not be captured when not sampled")
+	}
+
+	sampled = true
+	if errors.StackTrace(errors.New("testerr")) == nil {
+		t.Fatalf("stack trace should be captured when sampled")
+	}
+
+	if errors.SampleRate(0)() || !errors.SampleRate(1)() {
+		t.Fatalf("invalid errors.SampleRate")
+	}
+}
@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	retryUnset int8 = iota
+	retryRetryable
+	retryPermanent
+)
+
+// Retryable mark err as retryable, [Retry] will retry it regardless of [RetryPolicy.ShouldRetry].
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return annotate(err, 1, true, func(a *annotations) { a.retry = retryRetryable })
+}
+
+// Permanent mark err as permanent, [Retry] will not retry it.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return annotate(err, 1, true, func(a *annotations) { a.retry = retryPermanent })
+}
+
+type retryMarker interface {
+	retryMarker() int8
+}
+
+func (e *traced[E]) retryMarker() int8 { return e.retry }
+
+// return the outermost mark in err
+func findRetryMarker(err error) int8 {
+	for err != nil {
+		if m, ok := err.(retryMarker); ok {
+			if mark := m.retryMarker(); mark != retryUnset {
+				return mark
+			}
+		}
+		if errs := UnwrapSlice(err); errs != nil {
+			for _, e := range errs {
+				if mark := findRetryMarker(e); mark != retryUnset {
+					return mark
+				}
+			}
+			return retryUnset
+		}
+		err = Unwrap(err)
+	}
+	return retryUnset
+}
+
+// RetryPolicy control how [Retry] retry the function.
+//
+// the zero value is valid policy, see each field for the default.
+type RetryPolicy struct {
+	// maximum number of attempts, zero mean 3, negative mean unlimited (until the context is done).
+	MaxAttempts int
+
+	// delay before the second attempt, zero mean 100ms.
+	InitialDelay time.Duration
+
+	// maximum delay between attempts, zero mean 30s.
+	MaxDelay time.Duration
+
+	// the delay is multiplied by this value after each attempt, zero mean 2.
+	Multiplier float64
+
+	// randomize the delay by reducing it up to this fraction (0.0 to 1.0), zero mean no jitter.
+	Jitter float64
+
+	// decide whether err should be retried, nil mean every error is retried.
+	//
+	// it is not called for error marked by [Retryable] or [Permanent].
+	ShouldRetry func(err error) bool
+}
+
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	initial, maxDelay, multiplier := p.InitialDelay, p.MaxDelay, p.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	d := float64(initial)
+	for i := 1; i < attempt && d < float64(maxDelay); i++ {
+		d *= multiplier
+	}
+	d = min(d, float64(maxDelay))
+
+	if p.Jitter > 0 {
+		d -= d * min(p.Jitter, 1) * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
+
+func (p *RetryPolicy) shouldRetry(err error) bool {
+	switch findRetryMarker(err) {
+	case retryRetryable:
+		return true
+	case retryPermanent:
+		return false
+	}
+	return p.ShouldRetry == nil || p.ShouldRetry(err)
+}
+
+// the number of errors from the first and the last attempts kept by [Retry]
+const retryKeptErrors = 5
+
+// Retry call f until it return nil, with exponential backoff between attempts, see [RetryPolicy].
+//
+// if f never succeed, the returned error is joined errors of the attempts (each have "attempt" field, see [Fields]),
+// followed by the context error if the context is done. only the errors of the first 5 and the last 5 attempts are kept,
+// so unlimited retry doesn't grow the memory usage.
+func Retry(ctx context.Context, policy RetryPolicy, f func(ctx context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = 3
+	}
+
+	var errs []error
+	attempts, omitted := 0, 0
+	for {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			errs = append(errs, ctxErr)
+			break
+		}
+
+		attempts++
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+		if len(errs) == 2*retryKeptErrors {
+			errs = append(errs[:retryKeptErrors], errs[retryKeptErrors+1:]...)
+			omitted++
+		}
+		errs = append(errs, withFields(err, []Field{{"attempt", attempts}}, 1))
+
+		if !policy.shouldRetry(err) || (maxAttempts > 0 && attempts >= maxAttempts) {
+			break
+		}
+
+		timer := time.NewTimer(policy.delay(attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	msg := fmt.Sprintf("retry failed after %d attempts", attempts)
+	if omitted > 0 {
+		msg += fmt.Sprintf(" (%d attempts omitted)", omitted)
+	}
+	return &traced[error]{
+		stack: captureStack(1, false),
+		e:     fmt.Errorf("%s: %w", msg, Join(errs...)),
+	}
+}
+
+// like [Retry] but suitable for function that return 2 values.
+func Retry2[Ret any](ctx context.Context, policy RetryPolicy, f func(ctx context.Context) (Ret, error)) (Ret, error) {
+	var ret Ret
+	return ret, Retry(ctx, policy, func(ctx context.Context) error {
+		var err error
+		ret, err = f(ctx)
+		return err
+	})
+}
@@ -0,0 +1,147 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.winto.dev/errors"
+)
+
+var fastRetry = errors.RetryPolicy{InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestRetrySuccess(t *testing.T) {
+	attempts := 0
+	ret, err := errors.Retry2(context.Background(), fastRetry, func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, errors.New("testerr")
+		}
+		return 10, nil
+	})
+	if err != nil || ret != 10 || attempts != 3 {
+		t.Fatalf("errors.Retry2 should succeed on third attempt")
+	}
+}
+
+func TestRetryFailed(t *testing.T) {
+	attempts := 0
+	err := errors.Retry(context.Background(), fastRetry, func(ctx context.Context) error {
+		attempts++
+		var err error
+		funcAA(func() { err = errors.Errorf("testerr %d", attempts) })
+		return err
+	})
+
+	if attempts != 3 {
+		t.Fatalf("errors.Retry should attempt 3 times by default, got %d", attempts)
+	}
+
+	if err == nil || err.Error() != "retry failed after 3 attempts: testerr 1\ntesterr 2\ntesterr 3" {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	n := 0
+	for e, locs := range errors.StackTraces(err) {
+		if e == err {
+			continue
+		}
+		n++
+		if !haveTrace(locs, "funcAA") {
+			t.Fatalf("every attempt should keep its stack trace")
+		}
+		if fields := errors.Fields(e); len(fields) != 1 || fields[0] != (errors.Field{Key: "attempt", Value: n}) {
+			t.Fatalf("every attempt should have attempt field, got %v", fields)
+		}
+	}
+	if n != 3 {
+		t.Fatalf("every attempt should be kept")
+	}
+}
+
+func TestRetryMarker(t *testing.T) {
+	policy := fastRetry
+	policy.ShouldRetry = func(err error) bool { return false }
+
+	attempts := 0
+	errors.Retry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		return fmt.Errorf("wrapper: %w", errors.Retryable(errors.New("testerr")))
+	})
+	if attempts != 3 {
+		t.Fatalf("errors.Retryable should be retried regardless of ShouldRetry")
+	}
+
+	attempts = 0
+	errors.Retry(context.Background(), fastRetry, func(ctx context.Context) error {
+		attempts++
+		return errors.Permanent(fmt.Errorf("testerr"))
+	})
+	if attempts != 1 {
+		t.Fatalf("errors.Permanent should not be retried")
+	}
+
+	attempts = 0
+	errors.Retry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		return errors.New("testerr")
+	})
+	if attempts != 1 {
+		t.Fatalf("ShouldRetry should be respected")
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	policy := fastRetry
+	policy.MaxAttempts = -1
+	policy.Jitter = 0.5
+	err := errors.Retry(ctx, policy, func(ctx context.Context) error {
+		return errors.New("testerr")
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("errors.Retry should stop when context is done, got %v", err)
+	}
+}
+
+var errRetrySentinel = errors.New("sentinel")
+
+func TestRetrySentinel(t *testing.T) {
+	err := errors.Retry(context.Background(), fastRetry, func(ctx context.Context) error {
+		return errRetrySentinel
+	})
+	if !errors.Is(err, errRetrySentinel) {
+		t.Errorf("errors.Retry should keep errors.Is of the attempt errors")
+	}
+
+	if !errors.Is(errors.Retryable(errRetrySentinel), errRetrySentinel) ||
+		!errors.Is(errors.Permanent(errRetrySentinel), errRetrySentinel) {
+		t.Errorf("errors.Retryable and errors.Permanent should keep errors.Is")
+	}
+}
+
+func TestRetryOmitted(t *testing.T) {
+	policy := errors.RetryPolicy{MaxAttempts: 20, InitialDelay: time.Microsecond, MaxDelay: time.Microsecond}
+	attempts := 0
+	err := errors.Retry(context.Background(), policy, func(ctx context.Context) error {
+		attempts++
+		return errors.Errorf("testerr %d", attempts)
+	})
+
+	var attemptFields []any
+	for _, field := range errors.Fields(err) {
+		attemptFields = append(attemptFields, field.Value)
+	}
+	if fmt.Sprint(attemptFields) != "[1 2 3 4 5 16 17 18 19 20]" {
+		t.Errorf("errors.Retry should only keep the first and the last attempts, got %v", attemptFields)
+	}
+
+	if !strings.HasPrefix(err.Error(), "retry failed after 20 attempts (10 attempts omitted): testerr 1\n") {
+		t.Errorf("invalid error: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SnippetOptions control how [FormatWithSnippets] render the source snippets.
+//
+// the zero value is valid options, see each field for the default.
+type SnippetOptions struct {
+	// maximum number of locations in each error layer that have snippet, zero mean 1.
+	MaxLocations int
+
+	// number of lines shown before and after the failing line, zero mean 2.
+	Context int
+
+	// filter what location to include in the formated string,
+	// nil mean the filter set by [SetFormatFilterPkgs].
+	Include func(Location) bool
+
+	// if true, the snippet is colored with ANSI escape code.
+	Color bool
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[1;31m"
+)
+
+// FormatWithSnippets is like [Format], but it also show source code around the location,
+// intended for local development, as it need the source file to be available.
+//
+// the returned string is not stable, future version maybe returned different format.
+func FormatWithSnippets(err error, opts SnippetOptions) string {
+	maxLocations, contextLines, include := opts.MaxLocations, opts.Context, opts.Include
+	if maxLocations <= 0 {
+		maxLocations = 1
+	}
+	if contextLines <= 0 {
+		contextLines = 2
+	}
+	if include == nil {
+		include = defaultFilter
+	}
+
+	files := make(map[string][]string)
+	return formatChain(err, include, func(sb *strings.Builder, n int, l Location) {
+		if n >= maxLocations {
+			return
+		}
+
+		lines, ok := files[l.file]
+		if !ok {
+			if data, err := os.ReadFile(l.file); err == nil {
+				lines = strings.Split(string(data), "\n")
+			}
+			files[l.file] = lines
+		}
+		if l.line < 1 || l.line > len(lines) {
+			return
+		}
+
+		first, last := max(l.line-contextLines, 1), min(l.line+contextLines, len(lines))
+		width := len(strconv.Itoa(last))
+		for i := first; i <= last; i++ {
+			num := strconv.Itoa(i)
+			marker, color := "     ", ansiDim
+			if i == l.line {
+				marker, color = "   > ", ansiRed
+			}
+			if opts.Color {
+				sb.WriteString(color)
+			}
+			sb.WriteString(marker)
+			sb.WriteString(strings.Repeat(" ", width-len(num)))
+			sb.WriteString(num)
+			sb.WriteString(" | ")
+			sb.WriteString(strings.TrimRight(lines[i-1], "\r"))
+			if opts.Color {
+				sb.WriteString(ansiReset)
+			}
+			sb.WriteByte('\n')
+		}
+	})
+}
+
+// WriteWithSnippets write [FormatWithSnippets] of err to w.
+//
+// the snippet is colored if w is a terminal and NO_COLOR environment variable is not set.
+func WriteWithSnippets(w io.Writer, err error, opts SnippetOptions) error {
+	if !opts.Color && isTerminal(w) && os.Getenv("NO_COLOR") == "" {
+		opts.Color = true
+	}
+	_, werr := io.WriteString(w, FormatWithSnippets(err, opts))
+	return werr
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
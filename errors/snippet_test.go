@@ -0,0 +1,48 @@
+package errors_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+func TestFormatWithSnippets(t *testing.T) {
+	var err error
+	funcAA(func() {
+		err = errors.New("snippet err") // the failing line
+	})
+	err = errors.Errorf("wrapper: %w", err)
+
+	f := errors.FormatWithSnippets(err, errors.SnippetOptions{
+		Include: func(l errors.Location) bool { return l.InPkg("go.winto.dev/errors_test") },
+	})
+
+	if strings.Count(f, "   > ") != 2 ||
+		!strings.Contains(f, `err = errors.New("snippet err") // the failing line`) ||
+		!strings.Contains(f, `err = errors.Errorf("wrapper: %w", err)`) ||
+		strings.Contains(f, "\x1b[") {
+		t.Fatalf("invalid errors.FormatWithSnippets:\n%s", f)
+	}
+
+	f = errors.FormatWithSnippets(err, errors.SnippetOptions{
+		MaxLocations: 2,
+		Context:      1,
+		Color:        true,
+		Include:      func(l errors.Location) bool { return l.InPkg("go.winto.dev/errors_test") },
+	})
+
+	// the outer layer only have one location
+	if strings.Count(f, "   > ") != 3 || !strings.Contains(f, "\x1b[") {
+		t.Fatalf("invalid errors.FormatWithSnippets:\n%s", f)
+	}
+
+	var buf bytes.Buffer
+	if err := errors.WriteWithSnippets(&buf, err, errors.SnippetOptions{}); err != nil {
+		t.Fatalf("errors.WriteWithSnippets failed: %s", err.Error())
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("errors.WriteWithSnippets should not color non-terminal output")
+	}
+}
@@ -0,0 +1,63 @@
+package errors
+
+import "sync/atomic"
+
+var spawnTraceEnabled atomic.Bool
+
+// SetSpawnTrace enable or disable capturing spawn trace by [CaptureSpawn], disabled by default.
+func SetSpawnTrace(enabled bool) {
+	spawnTraceEnabled.Store(enabled)
+}
+
+type spawnTracer interface {
+	SpawnTrace() []Location
+}
+
+func (e *traced[E]) SpawnTrace() []Location { return e.spawn.locations() }
+
+func noSpawnTrace(err error) error { return err }
+
+// CaptureSpawn capture the caller locations, it should be called before spawning new goroutine.
+//
+// The returned function should be called in the new goroutine with the error returned by [Catch],
+// it will attach the captured locations to the error, so [Format] will show them as "Spawned by" section.
+//
+// if spawn trace is disabled (see [SetSpawnTrace]), the returned function will return the error as is.
+func CaptureSpawn() func(error) error {
+	if !spawnTraceEnabled.Load() {
+		return noSpawnTrace
+	}
+
+	spawn := captureStack(1, false)
+	return func(err error) error {
+		if err == nil || spawn == nil {
+			return err
+		}
+
+		return annotate(err, 1, false, func(a *annotations) { a.spawn = spawn })
+	}
+}
+
+// Get spawn trace of err, see [CaptureSpawn].
+//
+// if err is spawned multiple times (e.g. the error is propagated through multiple goroutines),
+// the outermost spawn trace is returned.
+func SpawnTrace(err error) []Location {
+	for err != nil {
+		if s, ok := err.(spawnTracer); ok {
+			if locs := s.SpawnTrace(); len(locs) > 0 {
+				return locs
+			}
+		}
+		if errs := UnwrapSlice(err); errs != nil {
+			for _, e := range errs {
+				if locs := SpawnTrace(e); len(locs) > 0 {
+					return locs
+				}
+			}
+			return nil
+		}
+		err = Unwrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+func spawnCatch(f func() error) error {
+	spawned := errors.CaptureSpawn()
+	ch := make(chan error, 1)
+	go func() { ch <- spawned(errors.Catch(f)) }()
+	return <-ch
+}
+
+func TestSpawnTraceDisabled(t *testing.T) {
+	var err error
+	funcAA(func() {
+		err = spawnCatch(func() error { panic("testpanic") })
+	})
+
+	if errors.SpawnTrace(err) != nil {
+		t.Fatalf("errors.SpawnTrace should be nil when spawn trace is disabled")
+	}
+}
+
+func TestSpawnTrace(t *testing.T) {
+	errors.SetSpawnTrace(true)
+	defer errors.SetSpawnTrace(false)
+
+	check := func(f func() error) {
+		var err error
+		funcAA(func() {
+			err = spawnCatch(func() error {
+				var err error
+				funcBB(func() { err = f() })
+				return err
+			})
+		})
+
+		if haveTrace(errors.StackTrace(err), "funcAA") || !haveTrace(errors.StackTrace(err), "funcBB") {
+			t.Errorf("errors.StackTrace should start inside the spawned goroutine")
+		}
+
+		if !haveTrace(errors.SpawnTrace(err), "funcAA") {
+			t.Errorf("errors.SpawnTrace should contains funcAA")
+		}
+
+		formatted := errors.Format(err)
+		if !strings.Contains(formatted, "Spawned by:\n") || strings.Count(formatted, "funcAA") != 1 {
+			t.Errorf("invalid errors.Format:\n%s", formatted)
+		}
+	}
+
+	check(func() error { panic("testpanic") })
+	check(func() error { panic(fmt.Errorf("testpanic")) })
+	check(func() error { return errors.New("testerr") })
+	check(func() error { return fmt.Errorf("wrapper: %w", errors.New("testerr")) })
+
+	if spawnCatch(func() error { return nil }) != nil {
+		t.Errorf("spawned nil error should be nil")
+	}
+}
+
+var errSpawnSentinel = errors.New("sentinel")
+
+func TestSpawnTraceIs(t *testing.T) {
+	errors.SetSpawnTrace(true)
+	defer errors.SetSpawnTrace(false)
+
+	err := spawnCatch(func() error { return errSpawnSentinel })
+	if !errors.Is(err, errSpawnSentinel) {
+		t.Errorf("errors.Is should still match the sentinel when spawn trace is enabled")
+	}
+
+	err = spawnCatch(func() error { return spawnCatch(func() error { return errSpawnSentinel }) })
+	if !errors.Is(err, errSpawnSentinel) {
+		t.Errorf("errors.Is should still match the sentinel when spawned multiple times")
+	}
+}
+
+func TestSpawnTraceNested(t *testing.T) {
+	errors.SetSpawnTrace(true)
+	defer errors.SetSpawnTrace(false)
+
+	var err error
+	funcAA(func() {
+		err = spawnCatch(func() error {
+			var err error
+			funcBB(func() { err = spawnCatch(func() error { return errors.New("testerr") }) })
+			return err
+		})
+	})
+
+	for name, formatted := range map[string]string{
+		"Format":     errors.Format(err),
+		"FormatTree": errors.FormatTree(err),
+	} {
+		if strings.Count(formatted, "Spawned by:\n") != 2 || !strings.Contains(formatted, "funcAA") || !strings.Contains(formatted, "funcBB") {
+			t.Errorf("errors.%s should contains both spawn traces:\n%s", name, formatted)
+		}
+	}
+
+	encoded, _ := json.Marshal(errors.Structured{Err: err})
+	var layer struct {
+		Type      string
+		SpawnedBy []any `json:"spawned_by"`
+		Causes    []struct {
+			SpawnedBy []any `json:"spawned_by"`
+		}
+	}
+	json.Unmarshal(encoded, &layer)
+	if len(layer.SpawnedBy) == 0 || len(layer.Causes) != 1 || len(layer.Causes[0].SpawnedBy) == 0 || strings.Contains(layer.Type, "traced") ||
+		!strings.Contains(string(encoded), "funcAA") || !strings.Contains(string(encoded), "funcBB") {
+		t.Fatalf("errors.Structured should contains both spawn traces: %s", encoded)
+	}
+}
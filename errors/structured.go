@@ -0,0 +1,218 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Structured is stable machine-readable representation of the error, including stack trace.
+//
+// It implement [json.Marshaler] and [slog.LogValuer], so it can be passed directly to
+// json encoder or slog logger, e.g.
+//
+//	slog.Error("request failed", "error", errors.Structured{Err: err})
+//
+// Each layer of the error chain is represented as an object with following keys:
+//
+//	message      the error message
+//	type         the go type of the error
+//	kind         the kind attached to the layer, see [WithKind]
+//	fields       the fields attached to the layer, see [WithFields], the outermost value win for duplicate keys
+//	stack_trace  list of location, each with file, line, and func key
+//	spawned_by   list of location where the goroutine is spawned, see [CaptureSpawn]
+//	causes       list of layer wrapped by this layer, more than one when the layer is created by [Join] or multiple %w
+//
+// In [slog.Value] representation, list is represented as group keyed by the index.
+//
+// Unlike [Format], the representation is stable, future version will only add new keys.
+type Structured struct {
+	Err error
+}
+
+type structuredLayer struct {
+	Message    string               `json:"message"`
+	Type       string               `json:"type"`
+	Kind       string               `json:"kind,omitempty"`
+	Fields     map[string]any       `json:"fields,omitempty"`
+	StackTrace []structuredLocation `json:"stack_trace,omitempty"`
+	SpawnedBy  []structuredLocation `json:"spawned_by,omitempty"`
+	Causes     []structuredLayer    `json:"causes,omitempty"`
+}
+
+type structuredLocation struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Func string `json:"func,omitempty"`
+}
+
+// MarshalJSON implement [json.Marshaler].
+//
+// nil error is encoded as json null.
+func (s Structured) MarshalJSON() ([]byte, error) {
+	if s.Err == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(newStructuredLayer(s.Err))
+}
+
+// LogValue implement [slog.LogValuer].
+func (s Structured) LogValue() slog.Value {
+	if s.Err == nil {
+		return slog.AnyValue(nil)
+	}
+	return newStructuredLayer(s.Err).logValue()
+}
+
+func newStructuredLayer(err error) structuredLayer {
+	err = mergeAnnotations(err)
+	value, causes := layerOf(err)
+
+	l := structuredLayer{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", value),
+	}
+
+	if k, ok := err.(kinder); ok && k.Kind() != KindUnknown {
+		l.Kind = k.Kind().String()
+	}
+
+	if f, ok := err.(fielder); ok {
+		for _, field := range f.Fields() {
+			if l.Fields == nil {
+				l.Fields = make(map[string]any)
+			}
+			if _, ok := l.Fields[field.Key]; ok {
+				continue // the outermost value win, see [Fields]
+			}
+			l.Fields[field.Key] = structuredFieldValue(field.Value)
+		}
+	}
+
+	if traced, ok := err.(stacktracer); ok {
+		for _, loc := range traced.StackTrace() {
+			l.StackTrace = append(l.StackTrace, structuredLocation{loc.file, loc.line, loc.func_})
+		}
+	}
+
+	if spawned, ok := err.(spawnTracer); ok {
+		for _, loc := range spawned.SpawnTrace() {
+			l.SpawnedBy = append(l.SpawnedBy, structuredLocation{loc.file, loc.line, loc.func_})
+		}
+	}
+
+	for _, cause := range causes {
+		l.Causes = append(l.Causes, newStructuredLayer(cause))
+	}
+
+	return l
+}
+
+func structuredFieldValue(v any) any {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+func (l structuredLayer) logValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("message", l.Message),
+		slog.String("type", l.Type),
+	}
+
+	if l.Kind != "" {
+		attrs = append(attrs, slog.String("kind", l.Kind))
+	}
+
+	if len(l.Fields) > 0 {
+		fields := make([]slog.Attr, 0, len(l.Fields))
+		for k, v := range l.Fields {
+			fields = append(fields, slog.Any(k, v))
+		}
+		slices.SortFunc(fields, func(a, b slog.Attr) int { return strings.Compare(a.Key, b.Key) })
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fields...)})
+	}
+
+	if len(l.StackTrace) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "stack_trace", Value: structuredLocationsLogValue(l.StackTrace)})
+	}
+
+	if len(l.SpawnedBy) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "spawned_by", Value: structuredLocationsLogValue(l.SpawnedBy)})
+	}
+
+	if len(l.Causes) > 0 {
+		causes := make([]slog.Attr, len(l.Causes))
+		for i, cause := range l.Causes {
+			causes[i] = slog.Attr{Key: strconv.Itoa(i), Value: cause.logValue()}
+		}
+		attrs = append(attrs, slog.Attr{Key: "causes", Value: slog.GroupValue(causes...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func structuredLocationsLogValue(locs []structuredLocation) slog.Value {
+	attrs := make([]slog.Attr, len(locs))
+	for i, loc := range locs {
+		attrs[i] = slog.Group(strconv.Itoa(i),
+			slog.String("file", loc.File),
+			slog.Int("line", loc.Line),
+			slog.String("func", loc.Func),
+		)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// layerOf return the value represented by err and the errors wrapped by err.
+//
+// when err have stack trace and wrap other error (as returned by [New], [Errorf], and [Trace]),
+// the wrapped error is represented by err itself, as it have same message.
+func layerOf(err error) (value any, causes []error) {
+	if traced, ok := err.(*traced[any]); ok {
+		return traced.e, nil
+	}
+
+	if next, ok := unmergedLayer(err); ok {
+		// the wrapped error is rendered as its own layer, but both represent the same value
+		value, _ = layerOf(next)
+		return value, []error{next}
+	}
+
+	value = err
+	if _, ok := err.(stacktracer); ok {
+		if wrapped, ok := err.(unwrap); ok {
+			if inner := wrapped.Unwrap(); inner != nil {
+				value = inner
+				err = inner
+			}
+		}
+	}
+
+	if errs := UnwrapSlice(err); errs != nil {
+		for _, e := range errs {
+			if e != nil {
+				causes = append(causes, e)
+			}
+		}
+		return value, causes
+	}
+
+	if next := Unwrap(err); next != nil {
+		causes = []error{next}
+	}
+
+	return value, causes
+}
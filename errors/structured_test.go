@@ -0,0 +1,132 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+type structuredLocation struct {
+	File string
+	Line int
+	Func string
+}
+
+type structuredLayer struct {
+	Message    string
+	Type       string
+	Fields     map[string]any
+	StackTrace []structuredLocation `json:"stack_trace"`
+	Causes     []structuredLayer
+}
+
+func haveStructuredTrace(ls []structuredLocation, what string) bool {
+	for _, l := range ls {
+		if strings.Contains(l.Func, what) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStructuredJSON(t *testing.T) {
+	var errAa error
+	funcAA(func() { errAa = errors.WithField(errors.New("a"), "id", 10) })
+	var errBb error
+	funcBB(func() { errBb = errors.New("b") })
+	err := errors.Errorf("wrapper: %w", errors.Join(errAa, errBb))
+
+	data, jsonErr := json.Marshal(errors.Structured{Err: err})
+	if jsonErr != nil {
+		t.Fatalf("json.Marshal failed: %s", jsonErr.Error())
+	}
+
+	var root structuredLayer
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("json.Unmarshal failed: %s", err.Error())
+	}
+
+	if root.Message != err.Error() || !strings.Contains(root.Type, "wrapError") ||
+		len(root.StackTrace) == 0 || len(root.Causes) != 1 {
+		t.Fatalf("invalid root layer: %s", data)
+	}
+
+	join := root.Causes[0]
+	if !strings.Contains(join.Type, "joinError") || len(join.StackTrace) != 0 || len(join.Causes) != 2 {
+		t.Fatalf("invalid join layer: %s", data)
+	}
+
+	a, b := join.Causes[0], join.Causes[1]
+	if a.Message != "a" || a.Fields["id"] != float64(10) || !haveStructuredTrace(a.StackTrace, "funcAA") {
+		t.Fatalf("invalid first branch: %s", data)
+	}
+	if b.Message != "b" || !haveStructuredTrace(b.StackTrace, "funcBB") {
+		t.Fatalf("invalid second branch: %s", data)
+	}
+}
+
+func TestStructuredJSONDuplicateField(t *testing.T) {
+	err := errors.WithField(errors.WithField(errors.New("x"), "k", 1), "k", 2)
+
+	data, _ := json.Marshal(errors.Structured{Err: err})
+	var root structuredLayer
+	json.Unmarshal(data, &root)
+	if root.Fields["k"] != float64(2) {
+		t.Fatalf("the outermost field value should win: %s", data)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", errors.Structured{Err: err})
+	if !strings.Contains(buf.String(), `"fields":{"k":2}`) {
+		t.Fatalf("the outermost field value should win in slog: %s", buf.String())
+	}
+}
+
+func TestStructuredJSONNil(t *testing.T) {
+	data, err := json.Marshal(errors.Structured{})
+	if err != nil || string(data) != "null" {
+		t.Fatalf("nil error should be encoded as null")
+	}
+}
+
+func TestStructuredJSONNonTraced(t *testing.T) {
+	data, err := json.Marshal(errors.Structured{Err: fmt.Errorf("testerr")})
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %s", err.Error())
+	}
+	if string(data) != `{"message":"testerr","type":"*errors.errorString"}` {
+		t.Fatalf("invalid json: %s", data)
+	}
+}
+
+func TestStructuredSlog(t *testing.T) {
+	var err error
+	funcAA(func() { err = errors.WithField(errors.New("testerr"), "id", 10) })
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("failed", "error", errors.Structured{Err: err})
+
+	var record struct {
+		Error struct {
+			Message    string
+			Type       string
+			Fields     map[string]any
+			StackTrace map[string]structuredLocation `json:"stack_trace"`
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("json.Unmarshal failed: %s", err.Error())
+	}
+
+	if record.Error.Message != "testerr" ||
+		record.Error.Type != "*errors.errorString" ||
+		record.Error.Fields["id"] != float64(10) ||
+		!haveStructuredTrace([]structuredLocation{record.Error.StackTrace["0"], record.Error.StackTrace["1"]}, "funcAA") {
+		t.Fatalf("invalid slog output: %s", buf.String())
+	}
+}
@@ -3,8 +3,17 @@ package errors
 import "fmt"
 
 type traced[E any] struct {
-	locs []Location
-	e    E
+	stack *stack
+	e     E
+	annotations
+}
+
+// extra information attached to the traced error, see WithFields, CaptureSpawn, WithKind, Retryable, and Permanent function
+type annotations struct {
+	fields []Field
+	spawn  *stack
+	kind   Kind
+	retry  int8
 }
 
 type unwrapslice interface {
@@ -14,7 +23,7 @@ type stacktracer interface {
 	StackTrace() []Location
 }
 
-func (e *traced[E]) StackTrace() []Location { return e.locs }
+func (e *traced[E]) StackTrace() []Location { return e.stack.locations() }
 func (e *traced[E]) Unwrap() E              { return e.e }
 
 func (e *traced[E]) Error() string {
@@ -28,11 +37,17 @@ func (e *traced[E]) Error() string {
 func findTracedErr(err error, digErrSlices bool) stacktracer {
 	for err != nil {
 		switch v := err.(type) {
+		case *traced[error]:
+			if v.stack == nil { // only carrying annotations, see annotate function
+				err = v.e
+				continue
+			}
+			return v
 		case stacktracer:
 			return v
 		case unwrapslice: // see comment on traceIfNeeded function
 			if !digErrSlices {
-				return &traced[error]{nil, err}
+				return &traced[error]{e: err}
 			}
 			slices := v.Unwrap()
 			if len(slices) == 0 {
@@ -47,26 +62,107 @@ func findTracedErr(err error, digErrSlices bool) stacktracer {
 	return nil
 }
 
-func traceIfNeeded(err error, skip int) error {
+// annotate return new error wrapping err, with f applied to the annotations of the new layer.
+//
+// err is never copied, so [Is] still match err itself. the new layer only have stack trace
+// if capture is true and err doesn't have stack trace yet.
+func annotate(err error, skip int, capture bool, f func(*annotations)) error {
+	c := &traced[error]{e: err}
+	if capture && findTracedErr(err, false) == nil {
+		c.stack = captureStack(skip+1, false)
+	}
+	f(&c.annotations)
+	return c
+}
+
+// mergeAnnotations return err with the annotations of the outer annotation-only layers (see annotate function)
+// merged into the traced error they wrap, so they are rendered as single layer.
+//
+// the returned error is only for rendering, it must not be returned to the caller.
+func mergeAnnotations(err error) error {
+	outer, ok := err.(*traced[error])
+	if !ok || outer.stack != nil {
+		return err
+	}
+
+	switch inner := mergeAnnotations(outer.e).(type) {
+	case *traced[error]:
+		c := *inner
+		if !c.annotations.merge(&outer.annotations) {
+			return err
+		}
+		return &c
+	case *traced[any]:
+		c := *inner
+		if !c.annotations.merge(&outer.annotations) {
+			return err
+		}
+		return &c
+	}
+	return err
+}
+
+// merge the outer annotations into a, return false if they cannot be merged
+func (a *annotations) merge(outer *annotations) bool {
+	if outer.spawn != nil && a.spawn != nil { // keep both spawn trace
+		return false
+	}
+
+	if len(outer.fields) > 0 {
+		a.fields = appendFields(outer.fields, a.fields)
+	}
+	if outer.spawn != nil {
+		a.spawn = outer.spawn
+	}
+	if outer.kind != KindUnknown {
+		a.kind = outer.kind
+	}
+	if outer.retry != retryUnset {
+		a.retry = outer.retry
+	}
+	return true
+}
+
+// unmergedLayer return the traced error wrapped by err, if err is annotation-only layer
+// that mergeAnnotations cannot merge into it, so the wrapped error is rendered as its own layer.
+func unmergedLayer(err error) (error, bool) {
+	outer, ok := err.(*traced[error])
+	if !ok || outer.stack != nil {
+		return nil, false
+	}
+
+	switch outer.e.(type) {
+	case *traced[error], *traced[any]:
+		return outer.e, true
+	}
+	return nil, false
+}
+
+func traceIfNeeded(err error, skip int, force bool) error {
 	// assuming unwrapslice as already traced but without locations
 	// as the individual errors in the slice might have locations
 	if findTracedErr(err, false) != nil {
 		return err
 	}
 
-	return &traced[error]{getLocs(skip + 1), err}
+	stack := captureStack(skip+1, force)
+	if stack == nil { // see StackPolicy
+		return err
+	}
+
+	return &traced[error]{stack: stack, e: err}
 }
 
 // Trace will return new error that have stack trace
 //
-// will return same err if err already have stack trace
+// will return same err if err already have stack trace, or if the stack trace is not captured because of [StackPolicy]
 // use [Is] function to compare the returned error with others, because equality (==) operator will fail
 func Trace(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	return traceIfNeeded(err, 1)
+	return traceIfNeeded(err, 1, false)
 }
 
 // Get stack trace of err
@@ -76,3 +172,11 @@ func StackTrace(err error) []Location {
 	}
 	return nil
 }
+
+// Callers return the locations of the current goroutine stack, skip 0 mean the caller of Callers.
+//
+// unlike [Trace], the stack is always captured regardless of [StackPolicy] (except its MaxDepth),
+// it is intended for debugging tools that need the stack even when error tracing is disabled.
+func Callers(skip int) []Location {
+	return captureStack(skip+1, true).locations()
+}
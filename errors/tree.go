@@ -0,0 +1,137 @@
+package errors
+
+import (
+	"iter"
+	"strings"
+)
+
+// like FormatTree, but you can filter what location to include in the formated string
+func FormatTreeWithFilter(err error, include func(Location) bool) string {
+	var sb strings.Builder
+	writeTree(&sb, "", err, include)
+	return sb.String()
+}
+
+// like FormatTree, but you can filter pkg location to include in the formated string
+func FormatTreeWithFilterPkgs(err error, pkgs ...string) string {
+	return FormatTreeWithFilter(err, func(l Location) bool { return l.InPkg(pkgs...) })
+}
+
+// FormatTree is like [Format], but it also render every branch of errors created by [Join] or multiple %w.
+//
+// each branch is rendered indented with branch marker, e.g.
+//
+//	Error => wrapper: a\nb
+//	  Stack Trace:
+//	  - ...
+//	Caused by Error => a\nb
+//	  |-- Error => a
+//	  |     Stack Trace:
+//	  |     - ...
+//	  `-- Error => b
+//	        Stack Trace:
+//	        - ...
+//
+// the returned string is not stable, future version maybe returned different format.
+func FormatTree(err error) string {
+	return FormatTreeWithFilter(err, defaultFilter)
+}
+
+func writeTree(sb *strings.Builder, prefix string, err error, include func(Location) bool) {
+	firstError := true
+	for err != nil {
+		err = mergeAnnotations(err)
+		var layer strings.Builder
+		if firstError {
+			firstError = false
+		} else {
+			layer.WriteString("Caused by ")
+		}
+
+		layer.WriteString("Error => ")
+		layer.WriteString(makeOneLine(err.Error()))
+		layer.WriteByte('\n')
+
+		writeLayerDetails(&layer, err, include, nil)
+		writePrefixed(sb, prefix, prefix, layer.String())
+
+		_, causes := layerOf(err)
+		if len(causes) == 1 {
+			err = causes[0]
+			continue
+		}
+
+		for i, cause := range causes {
+			var branch strings.Builder
+			writeTree(&branch, "", cause, include)
+			if i == len(causes)-1 {
+				writePrefixed(sb, prefix+"  `-- ", prefix+"      ", branch.String())
+			} else {
+				writePrefixed(sb, prefix+"  |-- ", prefix+"  |   ", branch.String())
+			}
+		}
+		return
+	}
+}
+
+// write every line in str, the first line prefixed with first, the rest prefixed with rest
+func writePrefixed(sb *strings.Builder, first, rest, str string) {
+	for i, line := range strings.SplitAfter(strings.TrimSuffix(str, "\n"), "\n") {
+		if i == 0 {
+			sb.WriteString(first)
+		} else {
+			sb.WriteString(rest)
+		}
+		sb.WriteString(line)
+	}
+	sb.WriteByte('\n')
+}
+
+// StackTraces is like [StackTrace], but it return iterator over every traced error in the error tree,
+// including every branch of errors created by [Join] or multiple %w.
+//
+// the tree is visited in depth-first order, the outermost error come first.
+func StackTraces(err error) iter.Seq2[error, []Location] {
+	return func(yield func(error, []Location) bool) {
+		walkStackTraces(err, yield)
+	}
+}
+
+func walkStackTraces(err error, yield func(error, []Location) bool) bool {
+	for err != nil {
+		// annotation-only layers (see annotate function) are yielded together with the traced error they wrap
+		layer := err
+		for {
+			v, ok := err.(*traced[error])
+			if !ok || v.stack != nil {
+				break
+			}
+			err = v.e
+		}
+		if err == nil {
+			return true
+		}
+
+		if traced, ok := err.(stacktracer); ok {
+			if locs := traced.StackTrace(); len(locs) > 0 {
+				if !yield(layer, locs) {
+					return false
+				}
+			}
+		}
+
+		_, causes := layerOf(err)
+		if len(causes) == 1 {
+			err = causes[0]
+			continue
+		}
+
+		for _, cause := range causes {
+			if !walkStackTraces(cause, yield) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
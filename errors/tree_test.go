@@ -0,0 +1,73 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.winto.dev/errors"
+)
+
+func TestFormatTree(t *testing.T) {
+	var errAa error
+	funcAA(func() { errAa = errors.New("a") })
+	var errBb error
+	funcBB(func() { errBb = errors.Errorf("b: %w", errors.New("c")) })
+	err := errors.Errorf("wrapper: %w", errors.Join(errAa, errBb))
+
+	f := errors.FormatTree(err)
+
+	if !strings.HasPrefix(f, "Error => wrapper: a\\nb: c\n") ||
+		!strings.Contains(f, "Caused by Error => a\\nb: c\n") ||
+		!strings.Contains(f, "  |-- Error => a\n") ||
+		!strings.Contains(f, "  `-- Error => b: c\n") ||
+		!strings.Contains(f, "      Caused by Error => c\n") ||
+		!strings.Contains(f, "funcAA") ||
+		!strings.Contains(f, "funcBB") {
+		t.Fatalf("invalid errors.FormatTree:\n%s", f)
+	}
+
+	if strings.Contains(errors.FormatTreeWithFilterPkgs(err), "funcAA") {
+		t.Fatalf("invalid errors.FormatTreeWithFilterPkgs")
+	}
+}
+
+func TestFormatTreeSameAsFormat(t *testing.T) {
+	var err error
+	funcAA(func() {
+		funcBB(func() {
+			err = errors.New("err1")
+			err = errors.Errorf("err2: %w", err)
+		})
+	})
+
+	if errors.FormatTree(err) != errors.Format(err) {
+		t.Fatalf("errors.FormatTree should be same as errors.Format when there is no branch")
+	}
+}
+
+func TestStackTraces(t *testing.T) {
+	var errAa error
+	funcAA(func() { errAa = errors.New("a") })
+	var errBb error
+	funcBB(func() { errBb = errors.New("b") })
+	err := errors.Errorf("wrapper: %w", errors.Join(errAa, errBb))
+
+	var msgs []string
+	for e, locs := range errors.StackTraces(err) {
+		if len(locs) == 0 {
+			t.Fatalf("errors.StackTraces should not yield empty stack trace")
+		}
+		msgs = append(msgs, e.Error())
+	}
+
+	if len(msgs) != 3 || msgs[0] != err.Error() || msgs[1] != "a" || msgs[2] != "b" {
+		t.Fatalf("invalid errors.StackTraces: %q", msgs)
+	}
+
+	for e, locs := range errors.StackTraces(err) {
+		if e != err || len(locs) == 0 {
+			t.Fatalf("invalid errors.StackTraces")
+		}
+		break
+	}
+}
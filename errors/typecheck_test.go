@@ -4,6 +4,10 @@ var (
 	_ error       = (*traced[error])(nil)
 	_ stacktracer = (*traced[error])(nil)
 	_ unwrap      = (*traced[error])(nil)
+	_ fielder     = (*traced[error])(nil)
+	_ spawnTracer = (*traced[error])(nil)
+	_ kinder      = (*traced[error])(nil)
+	_ retryMarker = (*traced[error])(nil)
 
 	_ error       = (*traced[[]error])(nil)
 	_ stacktracer = (*traced[[]error])(nil)
@@ -11,4 +15,7 @@ var (
 
 	_ error       = (*traced[any])(nil)
 	_ stacktracer = (*traced[any])(nil)
+	_ fielder     = (*traced[any])(nil)
+	_ spawnTracer = (*traced[any])(nil)
+	_ kinder      = (*traced[any])(nil)
 )
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -18,10 +19,16 @@ type unwrap interface {
 
 // like Format, but you can filter what location to include in the formated string
 func FormatWithFilter(err error, include func(Location) bool) string {
+	return formatChain(err, include, nil)
+}
+
+// snippet is called after writing the n-th included stack trace location, can be nil
+func formatChain(err error, include func(Location) bool, snippet func(sb *strings.Builder, n int, l Location)) string {
 	var sb strings.Builder
 
 	firstError := true
 	add := func(err error) error {
+		err = mergeAnnotations(err)
 		if firstError {
 			firstError = false
 		} else {
@@ -32,20 +39,12 @@ func FormatWithFilter(err error, include func(Location) bool) string {
 		sb.WriteString(makeOneLine(err.Error()))
 		sb.WriteByte('\n')
 
+		writeLayerDetails(&sb, err, include, snippet)
+
+		if next, ok := unmergedLayer(err); ok {
+			return next
+		}
 		if traced, ok := err.(stacktracer); ok {
-			firstErrTrace := true
-			for _, l := range traced.StackTrace() {
-				if include != nil && !include(l) {
-					continue
-				}
-				if firstErrTrace {
-					sb.WriteString("  Stack Trace:\n")
-					firstErrTrace = false
-				}
-				sb.WriteString("  - ")
-				sb.WriteString(l.String())
-				sb.WriteByte('\n')
-			}
 			if wrapped, ok := traced.(unwrap); ok {
 				return Unwrap(wrapped.Unwrap())
 			}
@@ -62,6 +61,65 @@ func FormatWithFilter(err error, include func(Location) bool) string {
 	return sb.String()
 }
 
+// write kind, fields, stack trace, and spawn trace of the err, without the wrapped errors
+func writeLayerDetails(sb *strings.Builder, err error, include func(Location) bool, snippet func(sb *strings.Builder, n int, l Location)) {
+	if k, ok := err.(kinder); ok && k.Kind() != KindUnknown {
+		sb.WriteString("  Kind: ")
+		sb.WriteString(k.Kind().String())
+		sb.WriteByte('\n')
+	}
+
+	if f, ok := err.(fielder); ok {
+		firstField := true
+		for _, field := range f.Fields() {
+			if firstField {
+				sb.WriteString("  Fields:\n")
+				firstField = false
+			}
+			sb.WriteString("  - ")
+			sb.WriteString(makeOneLine(field.Key))
+			sb.WriteString(": ")
+			sb.WriteString(makeOneLine(fmt.Sprint(field.Value)))
+			sb.WriteByte('\n')
+		}
+	}
+
+	if traced, ok := err.(stacktracer); ok {
+		n := 0
+		for _, l := range traced.StackTrace() {
+			if include != nil && !include(l) {
+				continue
+			}
+			if n == 0 {
+				sb.WriteString("  Stack Trace:\n")
+			}
+			sb.WriteString("  - ")
+			sb.WriteString(l.String())
+			sb.WriteByte('\n')
+			if snippet != nil {
+				snippet(sb, n, l)
+			}
+			n++
+		}
+	}
+
+	if spawned, ok := err.(spawnTracer); ok {
+		firstSpawnTrace := true
+		for _, l := range spawned.SpawnTrace() {
+			if include != nil && !include(l) {
+				continue
+			}
+			if firstSpawnTrace {
+				sb.WriteString("  Spawned by:\n")
+				firstSpawnTrace = false
+			}
+			sb.WriteString("  - ")
+			sb.WriteString(l.String())
+			sb.WriteByte('\n')
+		}
+	}
+}
+
 // like Format, but you can filter pkg location to include in the formated string
 func FormatWithFilterPkgs(err error, pkgs ...string) string {
 	return FormatWithFilter(err, func(l Location) bool { return l.InPkg(pkgs...) })
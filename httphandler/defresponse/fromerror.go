@@ -0,0 +1,58 @@
+package defresponse
+
+import (
+	"errors"
+	"net/http"
+)
+
+type httpStatuser interface {
+	HTTPStatus() int
+}
+
+// FromError respond with status code derived from err.
+//
+// err or any error wrapped by it can implement following interface to specify the status code,
+// errors with kind from [go.winto.dev/errors] implement it.
+//
+//	HTTPStatus() int
+//
+// the outermost non-zero status code is used, if there is none, 500 is used.
+//
+// the message is err.Error() for status code less than 500, otherwise [http.StatusText],
+// so internal error is not leaked to the client.
+//
+// [go.winto.dev/errors]: https://pkg.go.dev/go.winto.dev/errors#WithKind
+// [http.StatusText]: https://pkg.go.dev/net/http#StatusText
+func FromError(err error) http.HandlerFunc {
+	status := findHTTPStatus(err)
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	message := http.StatusText(status)
+	if status < 500 && err != nil {
+		message = err.Error()
+	}
+
+	return Error(status, message)
+}
+
+func findHTTPStatus(err error) int {
+	for err != nil {
+		if s, ok := err.(httpStatuser); ok {
+			if status := s.HTTPStatus(); status != 0 {
+				return status
+			}
+		}
+		if u, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range u.Unwrap() {
+				if status := findHTTPStatus(e); status != 0 {
+					return status
+				}
+			}
+			return 0
+		}
+		err = errors.Unwrap(err)
+	}
+	return 0
+}
@@ -37,10 +37,18 @@ func ErrorFormatFilterPkgs(packages ...string) Opt {
 	}
 }
 
+// Enable spawn trace, so error from goroutine spawned by [Go] will have the caller locations, see [errors.SetSpawnTrace].
+func ErrorSpawnTrace() Opt {
+	return func(optParam) {
+		errors.SetSpawnTrace(true)
+	}
+}
+
 // Execute f with ctx that will be cancelled by SIGINT or SIGTERM, this function call os.Exit() after f returned or panic
 //
 // if the panic value throw by f is [ExitCode], it will be used as exit code,
-// otherwise it will print stack trace and exit with code 1.
+// otherwise it will print stack trace and exit with code based on the kind attached by [errors.WithKind] (1 if there is none),
+// errors from standard library are not classified (see [errors.AttachedKind]), so context.Canceled still exit with 1.
 //
 // Exec cannot be called twice.
 func Exec(f func(ctx context.Context), opts ...Opt) {
@@ -90,7 +98,7 @@ func Exec(f func(ctx context.Context), opts ...Opt) {
 		return
 	}
 
-	exitCode = 1
+	exitCode = errors.AttachedKind(err).ExitCode()
 	doLogError(err)
 }
 
@@ -119,8 +127,9 @@ func Go(f func()) {
 		os.Exit(1)
 	}
 
+	spawned := errors.CaptureSpawn()
 	go func() {
-		err := errors.Catch0(f)
+		err := spawned(errors.Catch0(f))
 		if err == nil {
 			return
 		}
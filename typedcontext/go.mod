@@ -1,3 +1,5 @@
 module go.winto.dev/typedcontext
 
-go 1.20
+go 1.26.2
+
+require go.winto.dev/errors v1.8.0
@@ -0,0 +1,307 @@
+package typedcontext
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.winto.dev/errors"
+)
+
+// Scope decide how long value created by provider is shared, see [Provide].
+type Scope int
+
+const (
+	// the value is created once per [Registry], and shared by every scope.
+	//
+	// the provider receive context that is not tied to any request scope,
+	// so it cannot depend on request scoped value.
+	AppScope Scope = iota
+
+	// the value is created once per scope created by [Registry.NewScope] (e.g. once per http request).
+	RequestScope
+)
+
+var (
+	// returned by [Resolve] when the type (or one of its dependencies) have no provider.
+	ErrNoProvider = stderrors.New("typedcontext: no provider")
+
+	// returned by [Resolve] when the dependencies have cycle, including cycle between concurrent resolutions.
+	ErrCycle = stderrors.New("typedcontext: dependency cycle")
+
+	// returned by [Resolve] when the context is not created by [Registry.NewScope].
+	ErrNoScope = stderrors.New("typedcontext: no scope in context")
+)
+
+// Registry is collection of providers, it is safe for concurrent use.
+type Registry struct {
+	mu        sync.Mutex
+	providers map[any]*provider
+	app       *scope
+	appCtx    context.Context
+}
+
+type provider struct {
+	scope Scope
+	desc  string
+	f     func(ctx context.Context) (any, error)
+}
+
+type scope struct {
+	registry  *Registry
+	request   bool
+	mu        sync.Mutex
+	instances map[any]*instance
+	waiting   map[*resolving]*instance // the instance each blocked resolution is waiting for, used for cycle detection
+}
+
+func newScope(r *Registry, request bool) *scope {
+	return &scope{
+		registry:  r,
+		request:   request,
+		instances: make(map[any]*instance),
+		waiting:   make(map[*resolving]*instance),
+	}
+}
+
+type instance struct {
+	done  bool
+	val   any
+	owner *resolving    // the resolution creating the value, nil if none
+	wait  chan struct{} // closed when the owner finished
+}
+
+type scopeKey struct{}
+
+// the chain of keys being resolved, used for cycle detection
+type resolving struct {
+	key    any
+	desc   string
+	parent *resolving
+}
+
+type resolvingKey struct{}
+
+// NewRegistry create new empty [Registry].
+func NewRegistry() *Registry {
+	r := &Registry{providers: make(map[any]*provider)}
+	r.app = newScope(r, false)
+	r.appCtx = context.WithValue(context.Background(), scopeKey{}, r.app)
+	return r
+}
+
+// Provide register f as the provider of T in r, replacing the previous one (if any).
+//
+// f is called lazily by [Resolve], at most once per scope, unless it return error.
+// f can resolve other registered types from the ctx passed to it.
+func Provide[T any](r *Registry, scope Scope, f func(ctx context.Context) (T, error)) {
+	provide(r, key[T]{}, scope, describe[T](""), f)
+}
+
+// Provide register f as the provider of the key in r, see [Provide].
+func (k Key[T]) Provide(r *Registry, scope Scope, f func(ctx context.Context) (T, error)) {
+	provide(r, k.info, scope, describe[T](k.info.name), f)
+}
+
+func provide[T any](r *Registry, key any, scope Scope, desc string, f func(ctx context.Context) (T, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[key] = &provider{scope, desc, func(ctx context.Context) (any, error) {
+		v, err := f(ctx)
+		return v, err
+	}}
+}
+
+// NewScope create new context with new request scope of r, values provided with [RequestScope] are created once per returned context.
+func (r *Registry) NewScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, newScope(r, true))
+}
+
+// Middleware create new request scope for every request, see [Registry.NewScope].
+//
+// it is compatible with httphandler.Chain.
+func (r *Registry) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		next(w, req.WithContext(r.NewScope(req.Context())))
+	}
+}
+
+// Resolve return the value of T from ctx, value injected by [New] take precedence,
+// otherwise it is created by the provider registered in the [Registry] of ctx.
+func Resolve[T any](ctx context.Context) (T, error) {
+	return resolve[T](ctx, key[T]{}, describe[T](""))
+}
+
+// like [Resolve] but panic if the value cannot be resolved.
+func MustResolve[T any](ctx context.Context) T {
+	v, err := Resolve[T](ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Resolve return the value of the key from ctx, see [Resolve].
+func (k Key[T]) Resolve(ctx context.Context) (T, error) {
+	return resolve[T](ctx, k.info, describe[T](k.info.name))
+}
+
+// like [Key.Resolve] but panic if the value cannot be resolved.
+func (k Key[T]) MustResolve(ctx context.Context) T {
+	v, err := k.Resolve(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func resolve[T any](ctx context.Context, key any, desc string) (ret T, err error) {
+	if v, ok := ctx.Value(key).(T); ok {
+		return v, nil
+	}
+
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	if s == nil {
+		return ret, errors.Errorf("%w, cannot resolve %s", ErrNoScope, desc)
+	}
+
+	v, err := s.resolve(ctx, key, desc)
+	if err != nil {
+		return ret, err
+	}
+	ret, _ = v.(T)
+	return ret, nil
+}
+
+func (s *scope) resolve(ctx context.Context, key any, desc string) (any, error) {
+	chain, _ := ctx.Value(resolvingKey{}).(*resolving)
+	for c := chain; c != nil; c = c.parent {
+		if c.key == key {
+			return nil, errors.Errorf("%w: %s", ErrCycle, chain.path(desc))
+		}
+	}
+
+	s.registry.mu.Lock()
+	p := s.registry.providers[key]
+	s.registry.mu.Unlock()
+	if p == nil {
+		return nil, errors.Errorf("%w for %s", ErrNoProvider, chain.path(desc))
+	}
+
+	target, base := s, ctx
+	if p.scope == AppScope {
+		target, base = s.registry.app, s.registry.appCtx
+	} else if !s.request {
+		return nil, errors.Errorf("%w, cannot resolve request scoped %s", ErrNoScope, chain.path(desc))
+	}
+
+	target.mu.Lock()
+	inst := target.instances[key]
+	if inst == nil {
+		inst = &instance{}
+		target.instances[key] = inst
+	}
+	for !inst.done && inst.owner != nil {
+		// created by concurrent resolution, waiting for it would deadlock if it is waiting for us
+		if chain != nil {
+			if target.waitCycle(chain, inst) {
+				target.mu.Unlock()
+				return nil, errors.Errorf("%w: %s (waiting for concurrent resolution)", ErrCycle, chain.path(desc))
+			}
+			target.waiting[chain] = inst
+		}
+		wait := inst.wait
+		target.mu.Unlock()
+		<-wait
+		target.mu.Lock()
+		delete(target.waiting, chain)
+	}
+	if inst.done {
+		target.mu.Unlock()
+		return inst.val, nil
+	}
+	node := &resolving{key, desc, chain}
+	inst.owner, inst.wait = node, make(chan struct{})
+	target.mu.Unlock()
+
+	v, err := target.create(inst, func() (any, error) {
+		return p.f(context.WithValue(base, resolvingKey{}, node))
+	})
+	if err != nil {
+		return nil, errors.Errorf("typedcontext: cannot create %s: %w", chain.path(desc), err)
+	}
+	return v, nil
+}
+
+// call f as the owner of inst, and wake up the resolutions waiting for it, even if f panic
+func (s *scope) create(inst *instance, f func() (any, error)) (v any, err error) {
+	returned := false
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		inst.owner = nil
+		close(inst.wait)
+		if returned && err == nil {
+			inst.val, inst.done = v, true
+		}
+	}()
+	v, err = f()
+	returned = true
+	return v, err
+}
+
+// report whether chain waiting for inst would close a wait-for cycle, s.mu must be held
+func (s *scope) waitCycle(chain *resolving, inst *instance) bool {
+	for i := 0; i <= len(s.waiting); i++ {
+		if inst == nil || inst.owner == nil {
+			return false
+		}
+		if chain.has(inst.owner) {
+			return true
+		}
+
+		// the instance the resolution of the owner is waiting for
+		var next *instance
+		for w, i := range s.waiting {
+			if w.has(inst.owner) {
+				next = i
+				break
+			}
+		}
+		inst = next
+	}
+	return false
+}
+
+// report whether n is c or one of its parents
+func (c *resolving) has(n *resolving) bool {
+	for ; c != nil; c = c.parent {
+		if c == n {
+			return true
+		}
+	}
+	return false
+}
+
+// return human readable path from the root of the chain to desc
+func (c *resolving) path(desc string) string {
+	parts := []string{desc}
+	for ; c != nil; c = c.parent {
+		parts = append(parts, c.desc)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " -> ")
+}
+
+func describe[T any](name string) string {
+	typ := reflect.TypeOf((*T)(nil)).Elem().String()
+	if name == "" {
+		return typ
+	}
+	return name + " (" + typ + ")"
+}
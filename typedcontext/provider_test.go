@@ -0,0 +1,176 @@
+package typedcontext
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testDB struct{ dsn string }
+
+type testUser struct {
+	db   *testDB
+	name string
+}
+
+func TestProvide(t *testing.T) {
+	dsn := NewKey[string]("dsn")
+
+	r := NewRegistry()
+	dbCount := 0
+	dsn.Provide(r, AppScope, func(ctx context.Context) (string, error) { return "postgres://", nil })
+	Provide(r, AppScope, func(ctx context.Context) (*testDB, error) {
+		dbCount++
+		return &testDB{dsn.MustResolve(ctx)}, nil
+	})
+	Provide(r, RequestScope, func(ctx context.Context) (*testUser, error) {
+		db, err := Resolve[*testDB](ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &testUser{db, MustGet[string](ctx)}, nil
+	})
+
+	ctx1 := r.NewScope(New(context.Background(), "alice"))
+	ctx2 := r.NewScope(New(context.Background(), "bob"))
+	u1, u2 := MustResolve[*testUser](ctx1), MustResolve[*testUser](ctx2)
+	if u1 != MustResolve[*testUser](ctx1) || u1 == u2 {
+		t.Fatal("request scoped value should be created once per scope")
+	}
+	if u1.name != "alice" || u2.name != "bob" || u1.db != u2.db || u1.db.dsn != "postgres://" || dbCount != 1 {
+		t.Fatal("unexpected value")
+	}
+}
+
+func TestProvideErrors(t *testing.T) {
+	type a struct{}
+	type b struct{}
+	type c struct{}
+
+	r := NewRegistry()
+	Provide(r, AppScope, func(ctx context.Context) (a, error) {
+		_, err := Resolve[b](ctx)
+		return a{}, err
+	})
+	Provide(r, AppScope, func(ctx context.Context) (b, error) {
+		_, err := Resolve[a](ctx)
+		return b{}, err
+	})
+	Provide(r, AppScope, func(ctx context.Context) (*testUser, error) {
+		_, err := Resolve[c](ctx)
+		return nil, err
+	})
+	Provide(r, RequestScope, func(ctx context.Context) (c, error) { return c{}, nil })
+
+	ctx := r.NewScope(context.Background())
+
+	if _, err := Resolve[*testDB](ctx); !errors.Is(err, ErrNoProvider) {
+		t.Fatal(err)
+	}
+
+	if _, err := Resolve[b](ctx); !errors.Is(err, ErrCycle) {
+		t.Fatal(err)
+	}
+
+	if _, err := Resolve[*testUser](ctx); !errors.Is(err, ErrNoScope) {
+		t.Fatal("app scoped provider should not depend on request scoped value", err)
+	}
+
+	if _, err := Resolve[c](context.Background()); !errors.Is(err, ErrNoScope) {
+		t.Fatal(err)
+	}
+}
+
+func TestProvideConcurrentCycle(t *testing.T) {
+	type a struct{}
+	type b struct{}
+
+	// make sure both providers are running before they resolve each other
+	var started sync.WaitGroup
+	var onceA, onceB sync.Once
+	started.Add(2)
+
+	r := NewRegistry()
+	Provide(r, RequestScope, func(ctx context.Context) (a, error) {
+		onceA.Do(started.Done)
+		started.Wait()
+		_, err := Resolve[b](ctx)
+		return a{}, err
+	})
+	Provide(r, RequestScope, func(ctx context.Context) (b, error) {
+		onceB.Do(started.Done)
+		started.Wait()
+		_, err := Resolve[a](ctx)
+		return b{}, err
+	})
+
+	ctx := r.NewScope(context.Background())
+	errs := make(chan error, 2)
+	go func() { _, err := Resolve[a](ctx); errs <- err }()
+	go func() { _, err := Resolve[b](ctx); errs <- err }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrCycle) {
+				t.Fatal(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("concurrent resolution with cycle should not deadlock")
+		}
+	}
+}
+
+func TestProvideConcurrent(t *testing.T) {
+	r := NewRegistry()
+	count := 0
+	Provide(r, AppScope, func(ctx context.Context) (*testDB, error) {
+		count++
+		time.Sleep(10 * time.Millisecond)
+		return &testDB{"postgres://"}, nil
+	})
+
+	var wg sync.WaitGroup
+	dbs := make([]*testDB, 10)
+	for i := range dbs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dbs[i] = MustResolve[*testDB](r.NewScope(context.Background()))
+		}(i)
+	}
+	wg.Wait()
+
+	for _, db := range dbs {
+		if db != dbs[0] {
+			t.Fatal("concurrent resolution should share the same value")
+		}
+	}
+	if count != 1 {
+		t.Fatal("provider should be called once")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := NewRegistry()
+	count := 0
+	Provide(r, RequestScope, func(ctx context.Context) (*testUser, error) {
+		count++
+		return &testUser{}, nil
+	})
+
+	h := r.Middleware(func(w http.ResponseWriter, req *http.Request) {
+		if MustResolve[*testUser](req.Context()) != MustResolve[*testUser](req.Context()) {
+			t.Fatal("should be the same value")
+		}
+	})
+	h(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	h(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if count != 2 {
+		t.Fatal(count)
+	}
+}
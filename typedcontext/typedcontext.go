@@ -3,13 +3,16 @@ package typedcontext
 
 import (
 	"context"
+	"reflect"
+
+	"go.winto.dev/errors"
 )
 
 type key[T any] struct{ _ [0]*T }
 
 // Create new context that have singleton value of the val's type.
 func New[T any](ctx context.Context, val T) context.Context {
-	return context.WithValue(ctx, key[T]{}, val)
+	return &valueCtx{ctx, key[T]{}, "", reflect.TypeOf((*T)(nil)).Elem(), val}
 }
 
 // Get the singleton value from the context.
@@ -18,7 +21,130 @@ func Get[T any](ctx context.Context) (T, bool) {
 	return v, ok
 }
 
-// like [Get] but panic if the value is not in the context.
+// like [Get] but panic with traced error naming the type if the value is not in the context.
 func MustGet[T any](ctx context.Context) T {
-	return ctx.Value(key[T]{}).(T)
+	v, ok := ctx.Value(key[T]{}).(T)
+	if !ok {
+		panic(errors.Errorf("typedcontext: no value of %s in the context", describe[T]("")))
+	}
+	return v
+}
+
+// Key is named key for value of type T, so multiple values of the same type can coexist in the context.
+//
+// Key must be created with [NewKey], usually as package level variable.
+type Key[T any] struct{ info *keyInfo }
+
+type keyInfo struct{ name string }
+
+// NewKey create new [Key], name is only used for debugging (see [List]), keys with the same name are still distinct.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{&keyInfo{name}}
+}
+
+// Name return the name of the key.
+func (k Key[T]) Name() string { return k.info.name }
+
+// Create new context that have the value of the key.
+func (k Key[T]) New(ctx context.Context, val T) context.Context {
+	return &valueCtx{ctx, k.info, k.info.name, reflect.TypeOf((*T)(nil)).Elem(), val}
+}
+
+// Get the value of the key from the context.
+func (k Key[T]) Get(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k.info).(T)
+	return v, ok
+}
+
+// like [Key.Get] but panic with traced error naming the key if the value is not in the context.
+func (k Key[T]) MustGet(ctx context.Context) T {
+	v, ok := ctx.Value(k.info).(T)
+	if !ok {
+		panic(errors.Errorf("typedcontext: no value of %s in the context", describe[T](k.info.name)))
+	}
+	return v
+}
+
+// context created by this package, it also respond to listKey, so [List] can walk the chain
+type valueCtx struct {
+	context.Context
+	key  any
+	name string
+	typ  reflect.Type
+	val  any
+}
+
+type listKey struct{}
+
+func (c *valueCtx) Value(key any) any {
+	if key == c.key {
+		return c.val
+	}
+	if key == (listKey{}) {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+// Entry is a value in the context, see [List].
+type Entry struct {
+	Name  string // the name of the [Key], empty for value created by [New]
+	Type  reflect.Type
+	Value any
+}
+
+func (e Entry) String() string {
+	if e.Name == "" {
+		return e.Type.String()
+	}
+	return e.Name + " (" + e.Type.String() + ")"
+}
+
+// return every visible valueCtx in the chain, the innermost come first
+func visibleValues(ctx context.Context) []*valueCtx {
+	var values []*valueCtx
+	seen := make(map[any]bool)
+	c, _ := ctx.Value(listKey{}).(*valueCtx)
+	for c != nil {
+		if !seen[c.key] {
+			seen[c.key] = true
+			values = append(values, c)
+		}
+		c, _ = c.Context.Value(listKey{}).(*valueCtx)
+	}
+	return values
+}
+
+// List return every value created by this package that is visible in the context, the innermost come first.
+//
+// it is intended for debugging, values shadowed by newer value of the same key are not listed.
+func List(ctx context.Context) []Entry {
+	var entries []Entry
+	for _, c := range visibleValues(ctx) {
+		entries = append(entries, Entry{c.name, c.typ, c.val})
+	}
+	return entries
+}
+
+// CopyValues return new context derived from dst that have every value created by this package in src,
+// including the scope created by [Registry.NewScope], but not other values nor the cancellation and deadline of src.
+//
+// it is useful for background goroutine spawned from a request, e.g.
+//
+//	mainpkg.Go(func() { doBackgroundWork(typedcontext.CopyValues(appCtx, r.Context())) })
+func CopyValues(dst, src context.Context) context.Context {
+	if s := src.Value(scopeKey{}); s != nil {
+		dst = context.WithValue(dst, scopeKey{}, s)
+	}
+	values := visibleValues(src)
+	for i := len(values) - 1; i >= 0; i-- {
+		c := values[i]
+		dst = &valueCtx{dst, c.key, c.name, c.typ, c.val}
+	}
+	return dst
+}
+
+// Detach is shorthand for [CopyValues] with [context.Background] as dst.
+func Detach(ctx context.Context) context.Context {
+	return CopyValues(context.Background(), ctx)
 }
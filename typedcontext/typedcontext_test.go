@@ -3,7 +3,10 @@ package typedcontext
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
+
+	"go.winto.dev/errors"
 )
 
 func TestNormalOperation(t *testing.T) {
@@ -64,3 +67,76 @@ func TestShouldWorkOnInterface(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestKey(t *testing.T) {
+	primary := NewKey[string]("primary")
+	replica := NewKey[string]("replica")
+
+	ctx := context.Background()
+	ctx = primary.New(ctx, "a")
+	ctx = replica.New(ctx, "b")
+	ctx = New(ctx, "c")
+
+	if primary.MustGet(ctx) != "a" || replica.MustGet(ctx) != "b" || MustGet[string](ctx) != "c" {
+		t.FailNow()
+	}
+	if _, ok := NewKey[string]("primary").Get(ctx); ok {
+		t.FailNow()
+	}
+}
+
+func TestList(t *testing.T) {
+	primary := NewKey[string]("primary")
+
+	ctx := context.Background()
+	ctx = New(ctx, 10)
+	ctx = primary.New(ctx, "a")
+	ctx = context.WithValue(ctx, reflect.TypeOf(20), 20)
+	ctx = New(ctx, 30)
+
+	entries := List(ctx)
+	if len(entries) != 2 {
+		t.Fatal(entries)
+	}
+	if entries[0].String() != "int" || entries[0].Value != 30 {
+		t.Fatal(entries[0])
+	}
+	if entries[1].String() != "primary (string)" || entries[1].Value != "a" {
+		t.Fatal(entries[1])
+	}
+}
+
+func TestMustGetPanicMessage(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !strings.Contains(err.Error(), "no value of primary (*strings.Builder)") || len(errors.StackTrace(err)) == 0 {
+			t.Fatal(err)
+		}
+	}()
+	NewKey[*strings.Builder]("primary").MustGet(context.Background())
+}
+
+func TestCopyValues(t *testing.T) {
+	primary := NewKey[string]("primary")
+	r := NewRegistry()
+	Provide(r, RequestScope, func(ctx context.Context) (*testUser, error) { return &testUser{}, nil })
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := r.NewScope(parent)
+	ctx = New(ctx, 10)
+	ctx = primary.New(ctx, "a")
+	ctx = New(ctx, 20)
+	ctx = context.WithValue(ctx, reflect.TypeOf(30), 30)
+	cancel()
+
+	detached := Detach(ctx)
+	if detached.Err() != nil {
+		t.Fatal("should not inherit cancellation")
+	}
+	if MustGet[int](detached) != 20 || primary.MustGet(detached) != "a" || detached.Value(reflect.TypeOf(30)) != nil {
+		t.Fatal("unexpected values")
+	}
+	if MustResolve[*testUser](ctx) != MustResolve[*testUser](detached) {
+		t.Fatal("should share the request scope")
+	}
+}